@@ -0,0 +1,611 @@
+package vidplatr
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ================================ //
+//   Frame-accurate edit preview    //
+// ================================ //
+
+// Segment names one [StartMs, EndMs) window of SourcePath within an
+// EditSpec's timeline — the same decomposition CopySection, DeleteSection,
+// AppendSection, and ReplaceSection compose internally.
+type Segment struct {
+	SourcePath string
+	StartMs    int64
+	EndMs      int64
+}
+
+// EditSpec describes a proposed edit as an ordered list of Segments,
+// without ever materializing a single output file for it.
+type EditSpec struct {
+	Segments []Segment
+}
+
+// NewEditHandler serves spec's timeline as a single synthetic fMP4 file
+// over HTTP, building only the fragments a request's byte Range actually
+// covers. This lets a web editor scrub a proposed edit before committing
+// it to disk.
+//
+// The fully-specified `Range: bytes=N-M` form is honored exactly, and the
+// open-ended `Range: bytes=N-` form (what real <video> elements send when
+// seeking, since they don't know the resource's total length either) is
+// served as a bounded openEndedRangeWindowBytes-sized 206 starting at N —
+// the client is expected to issue another Range request to continue past
+// the window. Any other Range (absent, suffix, multi-range) gets a plain
+// 200 response starting at byte 0, since satisfying those would require
+// knowing the edit's total byte length up front — defeating the point of
+// building fragments lazily. For the same reason Content-Range reports the
+// total as `*` (unknown), except when serving the range required building
+// every segment, in which case the now-known total length is reported.
+func NewEditHandler(spec EditSpec) http.Handler {
+	return &editHandler{
+		spec:  spec,
+		cache: DefaultEditFragmentCache(),
+		frags: make([][]byte, len(spec.Segments)),
+	}
+}
+
+type editHandler struct {
+	spec  EditSpec
+	cache *fragmentCache
+
+	mu    sync.Mutex
+	init  []byte
+	frags [][]byte
+}
+
+func (h *editHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	startByte, endByte, ranged, err := parseRangeHeader(r.Header.Get("Range"))
+	if err != nil {
+		w.Header().Set("Content-Range", "bytes */*")
+		http.Error(w, err.Error(), http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	if !ranged {
+		startByte, endByte = 0, noEndByte
+	} else if endByte == noEndByte {
+		// Open-ended `bytes=N-`: bound it to a window instead of trying to
+		// serve through the (unknown) end of the edit.
+		endByte = startByte + openEndedRangeWindowBytes - 1
+	}
+
+	init, err := h.ensureInit(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !ranged {
+		_, _, written, werr := h.writeWindow(ctx, w, init, startByte, endByte)
+		if werr != nil && written == 0 {
+			http.Error(w, werr.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Content-Range must be written before the body, so — unlike the
+	// unranged case above, which can stream straight to w — the window is
+	// buffered first: a short edit (or a request past its end) may not
+	// actually have endByte-startByte+1 bytes to give, and the response
+	// must not claim a range it didn't deliver.
+	var body bytes.Buffer
+	total, exhausted, _, werr := h.writeWindow(ctx, &body, init, startByte, endByte)
+	if werr != nil {
+		http.Error(w, werr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if exhausted && startByte >= total {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", total))
+		http.Error(w, "range start is beyond the end of the edit", http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+
+	actualEndByte, totalStr := endByte, "*"
+	if exhausted {
+		// Every segment was built, so the virtual file's total length — and
+		// hence the real end of this range — is now known.
+		actualEndByte, totalStr = total-1, strconv.FormatInt(total, 10)
+	}
+
+	w.Header().Set("Content-Type", "video/mp4")
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%s", startByte, actualEndByte, totalStr))
+	w.WriteHeader(http.StatusPartialContent)
+	_, _ = w.Write(body.Bytes())
+}
+
+// writeWindow writes init, then each segment's fragment, to w — clipping
+// each to [startByte, endByte] the way writeOverlap does — stopping once the
+// window is satisfied. exhausted is true only if every segment was built
+// (the window wasn't satisfied first), in which case total is the virtual
+// file's real, now-fully-known, byte length; otherwise total is 0 and the
+// real length remains unknown, since building the rest wasn't needed.
+func (h *editHandler) writeWindow(ctx context.Context, w io.Writer, init []byte, startByte, endByte int64) (total int64, exhausted bool, written int64, err error) {
+	offset := writeOverlap(w, init, 0, startByte, endByte, &written)
+
+	for i := range h.spec.Segments {
+		if offset > endByte {
+			return 0, false, written, nil
+		}
+		frag, ferr := h.ensureFragment(ctx, i)
+		if ferr != nil {
+			return 0, false, written, ferr
+		}
+		offset = writeOverlap(w, frag, offset, startByte, endByte, &written)
+	}
+	return offset, true, written, nil
+}
+
+// ensureInit returns the timeline's fMP4 init segment (ftyp+moov), building
+// segment 0 if neither this handler nor the on-disk cache has seen it
+// before. Every segment is served against this one init segment, so it's
+// only ever built once — but only after validateSegmentsShareInit confirms
+// every segment's source probes with the same video codec, profile, pixel
+// format, time base, sample aspect ratio, and audio codec as segment 0's.
+// Resolution, audio sample rate, and channel count are not compared.
+func (h *editHandler) ensureInit(ctx context.Context) ([]byte, error) {
+	h.mu.Lock()
+	init := h.init
+	h.mu.Unlock()
+	if init != nil {
+		return init, nil
+	}
+	if len(h.spec.Segments) == 0 {
+		return nil, errors.New("edit spec has no segments")
+	}
+	if err := validateSegmentsShareInit(ctx, h.spec.Segments); err != nil {
+		return nil, err
+	}
+
+	seg := h.spec.Segments[0]
+	key := fragCacheKey{kind: "init", sourcePath: seg.SourcePath, startMs: seg.StartMs, endMs: seg.EndMs}
+	if cached, ok := h.cache.get(key); ok {
+		h.mu.Lock()
+		h.init = cached
+		h.mu.Unlock()
+		return cached, nil
+	}
+
+	// Building segment 0 populates both h.init and h.frags[0].
+	if _, err := h.ensureFragment(ctx, 0); err != nil {
+		return nil, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.init, nil
+}
+
+// validateSegmentsShareInit rejects a spec whose segments don't actually
+// share the codec params ensureInit assumes when it builds the init segment
+// from Segments[0] alone and reuses it for every later segment. Sources are
+// probed once each (by path), since an EditSpec composing the same source
+// across several Segments is the common case.
+func validateSegmentsShareInit(ctx context.Context, segments []Segment) error {
+	if len(segments) < 2 {
+		return nil
+	}
+
+	want, err := probeSourceCodecParams(ctx, segments[0].SourcePath)
+	if err != nil {
+		return err
+	}
+
+	probed := map[string]bool{segments[0].SourcePath: true}
+	for _, seg := range segments[1:] {
+		if probed[seg.SourcePath] {
+			continue
+		}
+		probed[seg.SourcePath] = true
+
+		got, err := probeSourceCodecParams(ctx, seg.SourcePath)
+		if err != nil {
+			return err
+		}
+		if got != want {
+			return fmt.Errorf("edit preview: %s's codec params %+v don't match %s's %+v, so they can't share an init segment", seg.SourcePath, got, segments[0].SourcePath, want)
+		}
+	}
+	return nil
+}
+
+// ensureFragment returns the moof/mdat fragment bytes for spec.Segments[i],
+// checking this handler's own memory, then the shared on-disk LRU, before
+// falling back to an ffmpeg invocation scoped to just that segment.
+func (h *editHandler) ensureFragment(ctx context.Context, i int) ([]byte, error) {
+	h.mu.Lock()
+	if frag := h.frags[i]; frag != nil {
+		h.mu.Unlock()
+		return frag, nil
+	}
+	h.mu.Unlock()
+
+	seg := h.spec.Segments[i]
+	key := fragCacheKey{kind: "frag", sourcePath: seg.SourcePath, startMs: seg.StartMs, endMs: seg.EndMs}
+	if cached, ok := h.cache.get(key); ok {
+		h.mu.Lock()
+		h.frags[i] = cached
+		h.mu.Unlock()
+		return cached, nil
+	}
+
+	out, err := buildFragmentedMP4(ctx, seg)
+	if err != nil {
+		return nil, err
+	}
+	init, frag, err := splitMP4Init(out)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.cache.put(key, frag); err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	h.frags[i] = frag
+	if h.init == nil {
+		h.init = init
+		initKey := fragCacheKey{kind: "init", sourcePath: seg.SourcePath, startMs: seg.StartMs, endMs: seg.EndMs}
+		h.mu.Unlock()
+		_ = h.cache.put(initKey, init) // best-effort: already held in-memory for this handler's lifetime
+	} else {
+		h.mu.Unlock()
+	}
+	return frag, nil
+}
+
+// buildFragmentedMP4 builds the fragmented MP4 bytes for a single Segment.
+// When seg's source is smart-cut compatible, it decomposes the cut into the
+// same GOP-aware re-encoded head/tail slivers and stream-copied middle that
+// smartCutSection uses for CopySectionOpts, so the served window lands on
+// seg.StartMs/EndMs exactly instead of the nearest keyframes. It falls back
+// to cutKeyframeSnappedMP4 (same caveat as CopySection: the cut may only be
+// exact on keyframes) when the smart cut isn't profitable or the source
+// codec isn't one the concat demuxer can stitch back together.
+func buildFragmentedMP4(ctx context.Context, seg Segment) ([]byte, error) {
+	workDir, err := os.MkdirTemp("", "mvedit-editpreview-frag-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workDir)
+
+	parts, cleanup, ok, err := buildSmartCutParts(ctx, seg.SourcePath, workDir, ".mp4", seg.StartMs, seg.EndMs, DefaultEncoderProfile())
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return cutKeyframeSnappedMP4(ctx, seg.SourcePath, seg.StartMs, seg.EndMs)
+	}
+
+	stitchedPath := filepath.Join(workDir, "stitched.mp4")
+	listPath, listCleanup, err := createConcatListFile(workDir, parts)
+	if err != nil {
+		return nil, err
+	}
+	defer listCleanup()
+	if err := concatListCopyOrReencode(ctx, listPath, stitchedPath); err != nil {
+		return nil, err
+	}
+
+	return remuxToFragmentedMP4(ctx, stitchedPath)
+}
+
+// cutKeyframeSnappedMP4 runs ffmpeg against [startMs,endMs) of sourcePath,
+// stream-copying (no re-encode) into a fragmented MP4 written to stdout. As
+// with CopySection's plain stream-copy path, `-ss`/`-t` snap the cut to the
+// nearest keyframes, so the result may be off by up to one GOP.
+func cutKeyframeSnappedMP4(ctx context.Context, sourcePath string, startMs, endMs int64) ([]byte, error) {
+	startTimestamp := formatTimestampMs(startMs)
+	clipDuration := formatTimestampMs(endMs - startMs)
+
+	args := []string{
+		"-hide_banner",
+		"-loglevel", "error",
+		"-ss", startTimestamp,
+		"-t", clipDuration,
+		"-i", sourcePath,
+		"-map", "0:v:0",
+		"-map", "0:a?",
+		"-c", "copy",
+		"-f", "mp4",
+		"-movflags", "empty_moov+default_base_moof+frag_keyframe",
+		"pipe:1",
+	}
+	return runFFmpegStdout(ctx, args...)
+}
+
+// remuxToFragmentedMP4 remuxes an already-cut regular MP4 (moov at the
+// front) into the empty_moov/default_base_moof form ensureFragment and
+// splitMP4Init expect, without touching any stream.
+func remuxToFragmentedMP4(ctx context.Context, inputPath string) ([]byte, error) {
+	args := []string{
+		"-hide_banner",
+		"-loglevel", "error",
+		"-i", inputPath,
+		"-map", "0:v:0",
+		"-map", "0:a?",
+		"-c", "copy",
+		"-f", "mp4",
+		"-movflags", "empty_moov+default_base_moof+frag_keyframe",
+		"pipe:1",
+	}
+	return runFFmpegStdout(ctx, args...)
+}
+
+func runFFmpegStdout(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, errors.New("ffmpeg failed: " + msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+// splitMP4Init splits ffmpeg's fragmented-mp4 output into the leading
+// ftyp+moov "init segment" boxes and the trailing moof/mdat fragment boxes,
+// by walking top-level ISO-BMFF boxes.
+func splitMP4Init(data []byte) (init, frag []byte, err error) {
+	off := 0
+	for off < len(data) {
+		if off+8 > len(data) {
+			return nil, nil, errors.New("mp4: truncated box header")
+		}
+		size := int(binary.BigEndian.Uint32(data[off : off+4]))
+		boxType := string(data[off+4 : off+8])
+		if size == 0 {
+			size = len(data) - off
+		}
+		if size < 8 || off+size > len(data) {
+			return nil, nil, errors.New("mp4: invalid box size")
+		}
+		off += size
+		if boxType == "moov" {
+			return data[:off], data[off:], nil
+		}
+	}
+	return nil, nil, errors.New("mp4: no moov box found")
+}
+
+// noEndByte stands in for "no upper bound" in writeOverlap without risking
+// overflow when callers compute endByte+1. parseRangeHeader also returns it
+// as endByte for an open-ended `bytes=N-` range, as a signal for ServeHTTP
+// to substitute openEndedRangeWindowBytes before using it as a real bound.
+const noEndByte = int64(1)<<62 - 1
+
+// openEndedRangeWindowBytes is how much of the edit ServeHTTP serves for an
+// open-ended `bytes=N-` Range request, since it has no total length to serve
+// through to and a client needing more will simply request the next window.
+const openEndedRangeWindowBytes = 8 * 1024 * 1024
+
+// writeOverlap writes the portion of buf — which occupies byte range
+// [offset, offset+len(buf)) of the virtual concatenated file — that falls
+// within [startByte, endByte], and returns buf's end offset so the caller
+// can feed the next chunk in sequence.
+func writeOverlap(w io.Writer, buf []byte, offset, startByte, endByte int64, written *int64) int64 {
+	bufStart, bufEnd := offset, offset+int64(len(buf))
+	lo, hi := bufStart, bufEnd
+	if lo < startByte {
+		lo = startByte
+	}
+	if hi > endByte+1 {
+		hi = endByte + 1
+	}
+	if lo < hi {
+		n, _ := w.Write(buf[lo-bufStart : hi-bufStart])
+		*written += int64(n)
+	}
+	return bufEnd
+}
+
+// parseRangeHeader parses a single `Range: bytes=N-M` or open-ended
+// `bytes=N-` header into a byte window, returning endByte as noEndByte for
+// the open-ended form (ServeHTTP bounds it to openEndedRangeWindowBytes).
+// ok is false (with a nil error) for any header this handler doesn't serve
+// as a partial response — see NewEditHandler's doc comment. err is non-nil
+// only for a range this handler recognizes but can't satisfy (e.g. end
+// before start).
+func parseRangeHeader(header string) (startByte, endByte int64, ok bool, err error) {
+	header = strings.TrimSpace(header)
+	if header == "" || !strings.HasPrefix(header, "bytes=") {
+		return 0, 0, false, nil
+	}
+
+	spec := strings.TrimPrefix(header, "bytes=")
+	if strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+
+	parts := strings.SplitN(spec, "-", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return 0, 0, false, nil
+	}
+
+	start, startErr := strconv.ParseInt(parts[0], 10, 64)
+	if startErr != nil || start < 0 {
+		return 0, 0, false, nil
+	}
+
+	if parts[1] == "" {
+		return start, noEndByte, true, nil
+	}
+
+	end, endErr := strconv.ParseInt(parts[1], 10, 64)
+	if endErr != nil {
+		return 0, 0, false, nil
+	}
+	if end < start {
+		return 0, 0, false, errors.New("invalid byte range")
+	}
+	return start, end, true, nil
+}
+
+// ========================= //
+//  On-disk fragment cache   //
+// ========================= //
+
+// fragCacheKey identifies one cached blob: either the timeline's init
+// segment or one segment's fragment, named the same way the request that
+// produced it was named.
+type fragCacheKey struct {
+	kind       string // "init" or "frag"
+	sourcePath string
+	startMs    int64
+	endMs      int64
+}
+
+func (k fragCacheKey) filename() string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%s|%d|%d", k.kind, k.sourcePath, k.startMs, k.endMs)))
+	return hex.EncodeToString(sum[:]) + ".bin"
+}
+
+// fragmentCache is an LRU of fMP4 fragments persisted under dir, evicting
+// the least-recently-used entry once size exceeds maxBytes so repeatedly
+// scrubbing a timeline doesn't require re-running ffmpeg for segments the
+// caller already visited.
+type fragmentCache struct {
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	size  int64
+	order *list.List
+	elems map[fragCacheKey]*list.Element
+}
+
+type fragCacheEntry struct {
+	key  fragCacheKey
+	size int64
+}
+
+func newFragmentCache(dir string, maxBytes int64) *fragmentCache {
+	return &fragmentCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		elems:    make(map[fragCacheKey]*list.Element),
+	}
+}
+
+func (c *fragmentCache) path(key fragCacheKey) string {
+	return filepath.Join(c.dir, key.filename())
+}
+
+func (c *fragmentCache) get(key fragCacheKey) ([]byte, bool) {
+	c.mu.Lock()
+	elem, ok := c.elems[key]
+	if ok {
+		c.order.MoveToFront(elem)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+func (c *fragmentCache) put(key fragCacheKey, data []byte) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path(key), data, 0o600); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.elems[key]; ok {
+		c.size -= elem.Value.(*fragCacheEntry).size
+		c.order.Remove(elem)
+		delete(c.elems, key)
+	}
+
+	entry := &fragCacheEntry{key: key, size: int64(len(data))}
+	c.elems[key] = c.order.PushFront(entry)
+	c.size += entry.size
+
+	for c.size > c.maxBytes && c.order.Len() > 1 {
+		back := c.order.Back()
+		oldest := back.Value.(*fragCacheEntry)
+		c.order.Remove(back)
+		delete(c.elems, oldest.key)
+		c.size -= oldest.size
+		_ = os.Remove(c.path(oldest.key))
+	}
+	return nil
+}
+
+var (
+	defaultEditCacheOnce sync.Once
+	defaultEditCache     *fragmentCache
+
+	editCacheMu          sync.Mutex
+	editCacheDirOverride string
+	editCacheLimitBytes  int64 = 512 * 1024 * 1024
+)
+
+// SetEditCacheDir overrides the on-disk directory NewEditHandler's default
+// fragment cache uses. It only takes effect before the first call that
+// creates the default cache.
+func SetEditCacheDir(dir string) {
+	editCacheMu.Lock()
+	defer editCacheMu.Unlock()
+	editCacheDirOverride = dir
+}
+
+// SetEditCacheLimitBytes overrides the default fragment cache's eviction
+// threshold. It only takes effect before the first call that creates the
+// default cache.
+func SetEditCacheLimitBytes(limitBytes int64) {
+	editCacheMu.Lock()
+	defer editCacheMu.Unlock()
+	editCacheLimitBytes = limitBytes
+}
+
+// DefaultEditFragmentCache returns the package-wide fragment cache used by
+// NewEditHandler, lazily created on first use.
+func DefaultEditFragmentCache() *fragmentCache {
+	defaultEditCacheOnce.Do(func() {
+		editCacheMu.Lock()
+		dir, limitBytes := editCacheDirOverride, editCacheLimitBytes
+		editCacheMu.Unlock()
+		if dir == "" {
+			dir = filepath.Join(os.TempDir(), "vidplatr-editcache")
+		}
+		defaultEditCache = newFragmentCache(dir, limitBytes)
+	})
+	return defaultEditCache
+}
@@ -0,0 +1,152 @@
+package vidplatr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestWriteOverlap(t *testing.T) {
+	buf := []byte("0123456789")
+
+	tests := []struct {
+		name               string
+		offset             int64
+		startByte, endByte int64
+		want               string
+		wantOffset         int64
+	}{
+		{name: "fully inside window", offset: 0, startByte: 0, endByte: 100, want: "0123456789", wantOffset: 10},
+		{name: "fully before window", offset: 0, startByte: 20, endByte: 30, want: "", wantOffset: 10},
+		{name: "fully after window", offset: 100, startByte: 0, endByte: 10, want: "", wantOffset: 110},
+		{name: "window starts mid-buffer", offset: 0, startByte: 5, endByte: 100, want: "56789", wantOffset: 10},
+		{name: "window ends mid-buffer", offset: 0, startByte: 0, endByte: 4, want: "01234", wantOffset: 10},
+		{name: "window entirely inside buffer", offset: 0, startByte: 2, endByte: 6, want: "23456", wantOffset: 10},
+		{name: "non-zero base offset", offset: 20, startByte: 22, endByte: 24, want: "234", wantOffset: 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w bytes.Buffer
+			var written int64
+			gotOffset := writeOverlap(&w, buf, tt.offset, tt.startByte, tt.endByte, &written)
+			if gotOffset != tt.wantOffset {
+				t.Errorf("writeOverlap() offset = %d, want %d", gotOffset, tt.wantOffset)
+			}
+			if w.String() != tt.want {
+				t.Errorf("writeOverlap() wrote %q, want %q", w.String(), tt.want)
+			}
+			if written != int64(len(tt.want)) {
+				t.Errorf("writeOverlap() written = %d, want %d", written, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestWriteOverlapAccumulatesAcrossCalls(t *testing.T) {
+	var w bytes.Buffer
+	var written int64
+
+	offset := writeOverlap(&w, []byte("hello "), 0, 3, 8, &written)
+	offset = writeOverlap(&w, []byte("world"), offset, 3, 8, &written)
+
+	if got, want := w.String(), "lo wor"; got != want {
+		t.Errorf("accumulated write = %q, want %q", got, want)
+	}
+	if written != 6 {
+		t.Errorf("written = %d, want 6", written)
+	}
+	_ = offset
+}
+
+func box(boxType string, payload []byte) []byte {
+	b := make([]byte, 8+len(payload))
+	binary.BigEndian.PutUint32(b[0:4], uint32(len(b)))
+	copy(b[4:8], boxType)
+	copy(b[8:], payload)
+	return b
+}
+
+func TestSplitMP4Init(t *testing.T) {
+	ftyp := box("ftyp", []byte("isom"))
+	moov := box("moov", []byte("movie-metadata"))
+	moof := box("moof", []byte("fragment-header"))
+	mdat := box("mdat", []byte("frame-data"))
+
+	data := append(append(append(append([]byte{}, ftyp...), moov...), moof...), mdat...)
+
+	init, frag, err := splitMP4Init(data)
+	if err != nil {
+		t.Fatalf("splitMP4Init() error = %v", err)
+	}
+	if want := append(append([]byte{}, ftyp...), moov...); !bytes.Equal(init, want) {
+		t.Errorf("init = %v, want %v", init, want)
+	}
+	if want := append(append([]byte{}, moof...), mdat...); !bytes.Equal(frag, want) {
+		t.Errorf("frag = %v, want %v", frag, want)
+	}
+}
+
+func TestSplitMP4InitErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty input", data: nil},
+		{name: "truncated box header", data: []byte{0, 0, 0}},
+		{name: "no moov box", data: box("ftyp", []byte("isom"))},
+		{name: "box size smaller than header", data: []byte{0, 0, 0, 4, 'm', 'o', 'o', 'v'}},
+		{name: "box size overruns buffer", data: func() []byte {
+			b := box("moov", []byte("x"))
+			binary.BigEndian.PutUint32(b[0:4], uint32(len(b)+100))
+			return b
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := splitMP4Init(tt.data); err == nil {
+				t.Errorf("splitMP4Init(%v) error = nil, want non-nil", tt.data)
+			}
+		})
+	}
+}
+
+func TestParseRangeHeader(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		wantStart int64
+		wantEnd   int64
+		wantOK    bool
+		wantErr   bool
+	}{
+		{name: "absent", header: "", wantOK: false},
+		{name: "fully specified", header: "bytes=0-99", wantStart: 0, wantEnd: 99, wantOK: true},
+		{name: "mid-range", header: "bytes=100-199", wantStart: 100, wantEnd: 199, wantOK: true},
+		{name: "open-ended", header: "bytes=100-", wantStart: 100, wantEnd: noEndByte, wantOK: true},
+		{name: "suffix range unsupported", header: "bytes=-500", wantOK: false},
+		{name: "multi-range unsupported", header: "bytes=0-10,20-30", wantOK: false},
+		{name: "non-bytes unit", header: "items=0-10", wantOK: false},
+		{name: "garbage", header: "bytes=abc-def", wantOK: false},
+		{name: "end before start", header: "bytes=10-5", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok, err := parseRangeHeader(tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRangeHeader(%q) error = %v, wantErr %v", tt.header, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("parseRangeHeader(%q) ok = %v, want %v", tt.header, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("parseRangeHeader(%q) = (%d, %d), want (%d, %d)", tt.header, start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package vidplatr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// ========================= //
+//   Hardware Encoder Paths  //
+// ========================= //
+
+// EncoderProfile selects which ffmpeg video encoder a re-encode step uses.
+type EncoderProfile string
+
+const (
+	// SoftwareX264 is the always-available libx264/aac software path. It is
+	// the default when no other profile has been selected.
+	SoftwareX264 EncoderProfile = "software-x264"
+	// NVENC offloads encoding to an NVIDIA GPU via h264_nvenc.
+	NVENC EncoderProfile = "nvenc"
+	// VAAPI offloads encoding via VA-API (Intel/AMD on Linux).
+	VAAPI EncoderProfile = "vaapi"
+	// VideoToolbox offloads encoding via Apple's VideoToolbox (macOS).
+	VideoToolbox EncoderProfile = "videotoolbox"
+	// QSV offloads encoding via Intel Quick Sync Video.
+	QSV EncoderProfile = "qsv"
+)
+
+var (
+	defaultEncoderProfileMu sync.RWMutex
+	defaultEncoderProfile   = SoftwareX264
+)
+
+// SetDefaultEncoderProfile sets the package-wide default encoder used by
+// re-encode fallbacks (CopySection, concatListCopyOrReencode, etc.) that
+// don't request a profile explicitly.
+func SetDefaultEncoderProfile(profile EncoderProfile) {
+	defaultEncoderProfileMu.Lock()
+	defer defaultEncoderProfileMu.Unlock()
+	defaultEncoderProfile = profile
+}
+
+// DefaultEncoderProfile returns the current package-wide default encoder.
+func DefaultEncoderProfile() EncoderProfile {
+	defaultEncoderProfileMu.RLock()
+	defer defaultEncoderProfileMu.RUnlock()
+	return defaultEncoderProfile
+}
+
+// EncoderCapabilities records which hardware encoders ffmpeg reports as
+// built in, as returned by Probe.
+type EncoderCapabilities struct {
+	available map[EncoderProfile]bool
+}
+
+// Supports reports whether profile's encoder was found by Probe.
+func (c *EncoderCapabilities) Supports(profile EncoderProfile) bool {
+	if c == nil {
+		return profile == SoftwareX264
+	}
+	return c.available[profile]
+}
+
+// BestAvailable returns the first profile in preference order that Probe
+// found, falling back to SoftwareX264 if none did.
+func (c *EncoderCapabilities) BestAvailable(preference ...EncoderProfile) EncoderProfile {
+	for _, profile := range preference {
+		if c.Supports(profile) {
+			return profile
+		}
+	}
+	return SoftwareX264
+}
+
+var (
+	probeOnce sync.Once
+	probeCaps *EncoderCapabilities
+	probeErr  error
+)
+
+// Probe runs `ffmpeg -encoders` once (cached for the process lifetime) and
+// reports which hardware encoders are available, so callers can auto-select
+// a profile instead of hardcoding one.
+func Probe(ctx context.Context) (*EncoderCapabilities, error) {
+	probeOnce.Do(func() {
+		probeCaps, probeErr = probeEncoders(ctx)
+	})
+	return probeCaps, probeErr
+}
+
+func probeEncoders(ctx context.Context) (*EncoderCapabilities, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-encoders")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, errors.New("ffmpeg -encoders failed: " + msg)
+	}
+
+	caps := &EncoderCapabilities{available: map[EncoderProfile]bool{SoftwareX264: true}}
+	names := map[string]EncoderProfile{
+		"h264_nvenc":        NVENC,
+		"h264_vaapi":        VAAPI,
+		"h264_videotoolbox": VideoToolbox,
+		"h264_qsv":          QSV,
+	}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		for name, profile := range names {
+			if strings.Contains(line, name) {
+				caps.available[profile] = true
+			}
+		}
+	}
+	return caps, nil
+}
+
+// encoderFFmpegArgs translates profile into ffmpeg input-side hwaccel args
+// and video encode args for an H.264 output compatible with the rest of
+// the package's libx264 pipeline.
+func encoderFFmpegArgs(profile EncoderProfile) (hwaccelArgs, videoArgs []string) {
+	switch profile {
+	case NVENC:
+		return []string{"-hwaccel", "cuda", "-hwaccel_output_format", "cuda"},
+			[]string{"-c:v", "h264_nvenc", "-preset", "p4", "-rc", "vbr", "-cq", "23"}
+	case VAAPI:
+		return []string{"-vaapi_device", "/dev/dri/renderD128"},
+			[]string{"-vf", "format=nv12,hwupload", "-c:v", "h264_vaapi", "-qp", "23"}
+	case VideoToolbox:
+		return nil, []string{"-c:v", "h264_videotoolbox", "-q:v", "55"}
+	case QSV:
+		return nil, []string{"-c:v", "h264_qsv"}
+	default:
+		return nil, []string{"-c:v", "libx264", "-preset", "veryfast", "-crf", "20"}
+	}
+}
+
+// runFFmpegReencode builds and runs a re-encode command for profile via
+// buildArgs, retrying once with SoftwareX264 if the chosen hardware encoder
+// fails at runtime (e.g. the GPU isn't actually reachable despite being
+// installed).
+func runFFmpegReencode(ctx context.Context, profile EncoderProfile, buildArgs func(EncoderProfile) []string) (string, error) {
+	out, err := runFFmpeg(ctx, buildArgs(profile)...)
+	if err == nil {
+		return out, nil
+	}
+	if profile == SoftwareX264 {
+		return out, err
+	}
+	return runFFmpeg(ctx, buildArgs(SoftwareX264)...)
+}
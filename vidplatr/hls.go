@@ -0,0 +1,220 @@
+package vidplatr
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ========================= //
+//   HLS / fMP4 Segmenting   //
+// ========================= //
+
+// SegmentOptions controls fMP4 (CMAF) HLS segmenting via SegmentToHLS.
+type SegmentOptions struct {
+	// TargetDurationMs is the nominal segment length (`-hls_time`).
+	TargetDurationMs int64
+	// PartDurationMs, if set, enables LL-HLS parts (`-hls_part_time`).
+	PartDurationMs int64
+	// LowLatency switches the playlist type from vod to event
+	// (`-hls_playlist_type event`); it adds no other playlist flags.
+	LowLatency bool
+	// IndependentSegments sets the #EXT-X-INDEPENDENT-SEGMENTS flag.
+	IndependentSegments bool
+	// KeyInfoFile, if set, is passed to `-hls_key_info_file` to enable
+	// AES-128 sample encryption of the generated segments.
+	KeyInfoFile string
+}
+
+// PlaylistSegment describes one fMP4 media segment referenced by a
+// Playlist's .m3u8.
+type PlaylistSegment struct {
+	URI        string
+	DurationMs int64
+	// ByteRangeLength and ByteRangeOffset come from the segment's
+	// #EXT-X-BYTERANGE tag, for playlists that address several segments
+	// within one shared media file. SegmentToHLS emits one file per segment
+	// (`-hls_segment_filename seg%05d.m4s`), so it never writes that tag and
+	// these are always zero — callers should treat a zero ByteRangeLength as
+	// "the whole file at URI", not as an actual zero-length range.
+	ByteRangeLength int64
+	ByteRangeOffset int64
+}
+
+// Playlist is a parsed view of the .m3u8 SegmentToHLS produced, so callers
+// can serve segment URIs, byte ranges, and durations without re-parsing the
+// playlist file.
+type Playlist struct {
+	Dir            string
+	PlaylistPath   string
+	InitSegmentURI string
+	Segments       []PlaylistSegment
+	// TargetDurationMs is the playlist's #EXT-X-TARGETDURATION, in
+	// milliseconds (matching the rest of the package's timestamps).
+	TargetDurationMs int64
+}
+
+// SegmentToHLS produces a fMP4 (CMAF) HLS playlist plus init.mp4 and
+// numbered .m4s segments for inputPath in outDir.
+func SegmentToHLS(ctx context.Context, inputPath, outDir string, opts SegmentOptions) (*Playlist, error) {
+	if strings.TrimSpace(inputPath) == "" {
+		return nil, errors.New("inputPath is empty")
+	}
+	if strings.TrimSpace(outDir) == "" {
+		return nil, errors.New("outDir is empty")
+	}
+	if opts.TargetDurationMs <= 0 {
+		return nil, errors.New("opts.TargetDurationMs must be > 0")
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	const initName = "init.mp4"
+	const segmentPattern = "seg%05d.m4s"
+	playlistPath := filepath.Join(outDir, "playlist.m3u8")
+
+	hlsFlags := []string{"program_date_time"}
+	if opts.IndependentSegments {
+		hlsFlags = append([]string{"independent_segments"}, hlsFlags...)
+	}
+
+	ffmpegArgs := []string{
+		"-y",
+		"-hide_banner",
+		"-loglevel", "error",
+		"-i", inputPath,
+		"-c", "copy",
+		"-f", "hls",
+		"-hls_segment_type", "fmp4",
+		"-hls_time", strconv.FormatFloat(float64(opts.TargetDurationMs)/1000, 'f', -1, 64),
+		"-hls_playlist_type", "vod",
+		"-hls_fmp4_init_filename", initName,
+		"-hls_segment_filename", filepath.Join(outDir, segmentPattern),
+	}
+	if len(hlsFlags) > 0 {
+		ffmpegArgs = append(ffmpegArgs, "-hls_flags", strings.Join(hlsFlags, "+"))
+	}
+	if opts.PartDurationMs > 0 {
+		ffmpegArgs = append(ffmpegArgs, "-hls_part_time", strconv.FormatFloat(float64(opts.PartDurationMs)/1000, 'f', -1, 64))
+	}
+	if opts.LowLatency {
+		ffmpegArgs = append(ffmpegArgs, "-hls_playlist_type", "event")
+	}
+	if opts.KeyInfoFile != "" {
+		ffmpegArgs = append(ffmpegArgs, "-hls_key_info_file", opts.KeyInfoFile)
+	}
+	ffmpegArgs = append(ffmpegArgs, playlistPath)
+
+	if _, err := runFFmpeg(ctx, ffmpegArgs...); err != nil {
+		return nil, err
+	}
+
+	playlist, err := parsePlaylist(playlistPath)
+	if err != nil {
+		return nil, err
+	}
+	playlist.Dir = outDir
+	playlist.InitSegmentURI = initName
+	return playlist, nil
+}
+
+// SegmentSectionToHLS combines CopySection with SegmentToHLS so a UI can
+// request a scrubbable timeline for just [startMs,endMs] of inputPath.
+func SegmentSectionToHLS(ctx context.Context, inputPath, outDir string, startMs, endMs int64, opts SegmentOptions) (*Playlist, error) {
+	if strings.TrimSpace(inputPath) == "" {
+		return nil, errors.New("inputPath is empty")
+	}
+	if strings.TrimSpace(outDir) == "" {
+		return nil, errors.New("outDir is empty")
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	sectionPath, err := tempLike(filepath.Join(outDir, "section.mp4"), "mvedit-hls-section")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(sectionPath)
+
+	if err := CopySection(ctx, inputPath, sectionPath, startMs, endMs); err != nil {
+		return nil, err
+	}
+
+	return SegmentToHLS(ctx, sectionPath, outDir, opts)
+}
+
+// parsePlaylist reads a .m3u8 produced by SegmentToHLS and extracts segment
+// URIs, durations, and (if present) #EXT-X-BYTERANGE offsets.
+func parsePlaylist(playlistPath string) (*Playlist, error) {
+	f, err := os.Open(playlistPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	playlist := &Playlist{PlaylistPath: playlistPath}
+
+	scanner := bufio.NewScanner(f)
+	var pendingDurationMs int64
+	havePending := false
+	var pendingByteRangeLength, pendingByteRangeOffset, lastByteRangeEnd int64
+	havePendingByteRange := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "#EXT-X-TARGETDURATION:"):
+			secs, convErr := strconv.ParseFloat(strings.TrimPrefix(line, "#EXT-X-TARGETDURATION:"), 64)
+			if convErr == nil {
+				playlist.TargetDurationMs = int64(math.Round(secs * 1000))
+			}
+		case strings.HasPrefix(line, "#EXTINF:"):
+			rest := strings.TrimPrefix(line, "#EXTINF:")
+			durationStr, _, _ := strings.Cut(rest, ",")
+			secs, convErr := strconv.ParseFloat(durationStr, 64)
+			if convErr == nil {
+				pendingDurationMs = int64(math.Round(secs * 1000))
+				havePending = true
+			}
+		case strings.HasPrefix(line, "#EXT-X-BYTERANGE:"):
+			lengthStr, offsetStr, hasOffset := strings.Cut(strings.TrimPrefix(line, "#EXT-X-BYTERANGE:"), "@")
+			length, convErr := strconv.ParseInt(strings.TrimSpace(lengthStr), 10, 64)
+			if convErr != nil {
+				continue
+			}
+			pendingByteRangeLength = length
+			pendingByteRangeOffset = lastByteRangeEnd
+			if hasOffset {
+				if offset, convErr := strconv.ParseInt(strings.TrimSpace(offsetStr), 10, 64); convErr == nil {
+					pendingByteRangeOffset = offset
+				}
+			}
+			havePendingByteRange = true
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		default:
+			if havePending {
+				seg := PlaylistSegment{URI: line, DurationMs: pendingDurationMs}
+				if havePendingByteRange {
+					seg.ByteRangeLength = pendingByteRangeLength
+					seg.ByteRangeOffset = pendingByteRangeOffset
+					lastByteRangeEnd = pendingByteRangeOffset + pendingByteRangeLength
+					havePendingByteRange = false
+				}
+				playlist.Segments = append(playlist.Segments, seg)
+				havePending = false
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return playlist, nil
+}
@@ -0,0 +1,98 @@
+package vidplatr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePlaylist(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "playlist.m3u8")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestParsePlaylist(t *testing.T) {
+	const playlist = `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-TARGETDURATION:4
+#EXT-X-PLAYLIST-TYPE:VOD
+#EXTINF:4.004,
+seg00000.m4s
+#EXTINF:3.996,
+seg00001.m4s
+#EXT-X-ENDLIST
+`
+	path := writePlaylist(t, playlist)
+
+	got, err := parsePlaylist(path)
+	if err != nil {
+		t.Fatalf("parsePlaylist() error = %v", err)
+	}
+
+	if got.TargetDurationMs != 4000 {
+		t.Errorf("TargetDurationMs = %d, want 4000", got.TargetDurationMs)
+	}
+	want := []PlaylistSegment{
+		{URI: "seg00000.m4s", DurationMs: 4004},
+		{URI: "seg00001.m4s", DurationMs: 3996},
+	}
+	if len(got.Segments) != len(want) {
+		t.Fatalf("Segments = %+v, want %+v", got.Segments, want)
+	}
+	for i, seg := range got.Segments {
+		if seg != want[i] {
+			t.Errorf("Segments[%d] = %+v, want %+v", i, seg, want[i])
+		}
+	}
+}
+
+func TestParsePlaylistByteRanges(t *testing.T) {
+	// A playlist addressing several segments within one shared media file,
+	// the way a muxer emitting #EXT-X-BYTERANGE (rather than
+	// SegmentToHLS's one-file-per-segment output) would.
+	const playlist = `#EXTM3U
+#EXT-X-TARGETDURATION:2
+#EXTINF:2.000,
+#EXT-X-BYTERANGE:1000@0
+media.m4s
+#EXTINF:2.000,
+#EXT-X-BYTERANGE:1500
+media.m4s
+#EXTINF:2.000,
+#EXT-X-BYTERANGE:800@5000
+media.m4s
+#EXT-X-ENDLIST
+`
+	path := writePlaylist(t, playlist)
+
+	got, err := parsePlaylist(path)
+	if err != nil {
+		t.Fatalf("parsePlaylist() error = %v", err)
+	}
+
+	want := []PlaylistSegment{
+		{URI: "media.m4s", DurationMs: 2000, ByteRangeLength: 1000, ByteRangeOffset: 0},
+		// No explicit offset: continues immediately after the previous range.
+		{URI: "media.m4s", DurationMs: 2000, ByteRangeLength: 1500, ByteRangeOffset: 1000},
+		// Explicit offset: jumps ahead regardless of where the previous range ended.
+		{URI: "media.m4s", DurationMs: 2000, ByteRangeLength: 800, ByteRangeOffset: 5000},
+	}
+	if len(got.Segments) != len(want) {
+		t.Fatalf("Segments = %+v, want %+v", got.Segments, want)
+	}
+	for i, seg := range got.Segments {
+		if seg != want[i] {
+			t.Errorf("Segments[%d] = %+v, want %+v", i, seg, want[i])
+		}
+	}
+}
+
+func TestParsePlaylistMissingFile(t *testing.T) {
+	if _, err := parsePlaylist(filepath.Join(t.TempDir(), "missing.m3u8")); err == nil {
+		t.Error("parsePlaylist() error = nil, want non-nil")
+	}
+}
@@ -0,0 +1,299 @@
+package vidplatr
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ========================= //
+//      Async Job Manager    //
+// ========================= //
+
+// JobState is the lifecycle state of a Job.
+type JobState string
+
+const (
+	JobQueued   JobState = "queued"
+	JobRunning  JobState = "running"
+	JobDone     JobState = "done"
+	JobFailed   JobState = "failed"
+	JobCanceled JobState = "canceled"
+)
+
+// Job tracks a single async operation submitted to a JobManager.
+//
+// Progress is best-effort: it reflects the most recent ffmpeg invocation's
+// `out_time_ms` against the op's probed input duration, so for ops that run
+// ffmpeg more than once internally (DeleteSection, ReplaceSection, ...) it
+// can dip back down between invocations rather than climb smoothly to 1.
+type Job struct {
+	ID         string
+	Op         string
+	State      JobState
+	Progress   float64
+	DurationMs int64
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Err        error
+
+	mu          sync.Mutex
+	cancel      context.CancelFunc
+	subscribers []chan JobEvent
+}
+
+// JobEvent is published to a Job's subscribers on every state or progress
+// change.
+type JobEvent struct {
+	JobID    string
+	State    JobState
+	Progress float64
+	Err      error
+}
+
+// ProgressSink receives fractional progress updates (0..1) from a running
+// operation. Submitted funcs report through it as they make headway.
+type ProgressSink interface {
+	SetProgress(fraction float64)
+}
+
+// Snapshot returns a copy of the job's current fields, safe to read
+// concurrently with the job running.
+func (j *Job) Snapshot() Job {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return Job{
+		ID:         j.ID,
+		Op:         j.Op,
+		State:      j.State,
+		Progress:   j.Progress,
+		DurationMs: j.DurationMs,
+		StartedAt:  j.StartedAt,
+		FinishedAt: j.FinishedAt,
+		Err:        j.Err,
+	}
+}
+
+func (j *Job) setState(state JobState, err error) {
+	j.mu.Lock()
+	j.State = state
+	j.Err = err
+	if state == JobDone || state == JobFailed || state == JobCanceled {
+		j.FinishedAt = time.Now()
+	}
+	j.mu.Unlock()
+	j.publish()
+}
+
+// SetProgress implements ProgressSink.
+func (j *Job) SetProgress(fraction float64) {
+	if fraction < 0 {
+		fraction = 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	j.mu.Lock()
+	j.Progress = fraction
+	j.mu.Unlock()
+	j.publish()
+}
+
+func (j *Job) publish() {
+	j.mu.Lock()
+	evt := JobEvent{JobID: j.ID, State: j.State, Progress: j.Progress, Err: j.Err}
+	subs := make([]chan JobEvent, len(j.subscribers))
+	copy(subs, j.subscribers)
+	j.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- evt:
+		default:
+			// Slow subscriber: drop rather than block the job.
+		}
+	}
+}
+
+// JobManager runs submitted operations on a bounded worker pool so callers
+// can't accidentally fork an unbounded number of ffmpeg processes.
+type JobManager struct {
+	mu     sync.Mutex
+	jobs   map[string]*Job
+	sem    chan struct{}
+	nextID uint64
+}
+
+// NewJobManager creates a JobManager that runs at most maxConcurrent
+// operations at a time. maxConcurrent <= 0 is treated as 1.
+func NewJobManager(maxConcurrent int) *JobManager {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	return &JobManager{
+		jobs: make(map[string]*Job),
+		sem:  make(chan struct{}, maxConcurrent),
+	}
+}
+
+// Submit queues fn to run on the worker pool and returns its Job
+// immediately in the Queued state. fn is called with a context that's
+// canceled by Cancel, and a ProgressSink it may report progress through.
+func (m *JobManager) Submit(ctx context.Context, op string, fn func(ctx context.Context, sink ProgressSink) error) *Job {
+	jobCtx, cancel := context.WithCancel(ctx)
+
+	m.mu.Lock()
+	m.nextID++
+	job := &Job{
+		ID:     jobID(m.nextID),
+		Op:     op,
+		State:  JobQueued,
+		cancel: cancel,
+	}
+	m.jobs[job.ID] = job
+	m.mu.Unlock()
+
+	go func() {
+		select {
+		case m.sem <- struct{}{}:
+		case <-jobCtx.Done():
+			job.setState(JobCanceled, jobCtx.Err())
+			return
+		}
+		defer func() { <-m.sem }()
+
+		job.mu.Lock()
+		job.StartedAt = time.Now()
+		job.mu.Unlock()
+		job.setState(JobRunning, nil)
+
+		err := fn(jobCtx, job)
+
+		switch {
+		case errors.Is(jobCtx.Err(), context.Canceled) && err != nil:
+			job.setState(JobCanceled, jobCtx.Err())
+		case err != nil:
+			job.setState(JobFailed, err)
+		default:
+			job.SetProgress(1)
+			job.setState(JobDone, nil)
+		}
+	}()
+
+	return job
+}
+
+// Get returns the job with the given ID, if it exists.
+func (m *JobManager) Get(id string) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	return job, ok
+}
+
+// Cancel requests cancellation of the job's context. It returns an error if
+// the job doesn't exist; canceling an already-finished job is a no-op.
+func (m *JobManager) Cancel(id string) error {
+	job, ok := m.Get(id)
+	if !ok {
+		return errors.New("job not found: " + id)
+	}
+	job.mu.Lock()
+	cancel := job.cancel
+	job.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	return nil
+}
+
+// Subscribe returns a channel of JobEvents for the given job. The channel is
+// closed for the caller's own bookkeeping only in the sense that it's never
+// written to once the job reaches a terminal state and is garbage
+// collected with the Job; callers should stop reading once they observe a
+// terminal JobEvent.State.
+func (m *JobManager) Subscribe(id string) (<-chan JobEvent, bool) {
+	job, ok := m.Get(id)
+	if !ok {
+		return nil, false
+	}
+	ch := make(chan JobEvent, 16)
+	job.mu.Lock()
+	job.subscribers = append(job.subscribers, ch)
+	job.mu.Unlock()
+	return ch, true
+}
+
+func jobID(n uint64) string {
+	return "job-" + strconv.FormatUint(n, 10)
+}
+
+var (
+	defaultJobManagerOnce sync.Once
+	defaultJobManager     *JobManager
+)
+
+// DefaultJobManager returns the package-wide JobManager used by the
+// *Async helpers (CopySectionAsync, etc.), lazily created with a small
+// worker pool on first use.
+func DefaultJobManager() *JobManager {
+	defaultJobManagerOnce.Do(func() {
+		defaultJobManager = NewJobManager(4)
+	})
+	return defaultJobManager
+}
+
+// ========================= //
+//     Async op wrappers     //
+// ========================= //
+
+// CopySectionAsync is the async sibling of CopySection.
+func CopySectionAsync(ctx context.Context, inputPath, outputPath string, startMs, endMs int64) *Job {
+	return DefaultJobManager().Submit(ctx, "CopySection", func(ctx context.Context, sink ProgressSink) error {
+		return runTrackedByDuration(ctx, inputPath, sink, func(ctx context.Context) error {
+			return CopySection(ctx, inputPath, outputPath, startMs, endMs)
+		})
+	})
+}
+
+// DeleteSectionAsync is the async sibling of DeleteSection.
+func DeleteSectionAsync(ctx context.Context, inputPath, outputPath string, startMs, endMs int64) *Job {
+	return DefaultJobManager().Submit(ctx, "DeleteSection", func(ctx context.Context, sink ProgressSink) error {
+		return runTrackedByDuration(ctx, inputPath, sink, func(ctx context.Context) error {
+			return DeleteSection(ctx, inputPath, outputPath, startMs, endMs)
+		})
+	})
+}
+
+// ReplaceSectionAsync is the async sibling of ReplaceSection.
+func ReplaceSectionAsync(ctx context.Context, inputPath, replacePath, outputPath string, startMs, endMs int64) *Job {
+	return DefaultJobManager().Submit(ctx, "ReplaceSection", func(ctx context.Context, sink ProgressSink) error {
+		return runTrackedByDuration(ctx, inputPath, sink, func(ctx context.Context) error {
+			_, err := ReplaceSection(ctx, inputPath, replacePath, outputPath, startMs, endMs)
+			return err
+		})
+	})
+}
+
+// AppendSectionAsync is the async sibling of AppendSection.
+func AppendSectionAsync(ctx context.Context, inputPath, appendPath, outputPath string, insertMs int64) *Job {
+	return DefaultJobManager().Submit(ctx, "AppendSection", func(ctx context.Context, sink ProgressSink) error {
+		return runTrackedByDuration(ctx, inputPath, sink, func(ctx context.Context) error {
+			_, err := AppendSection(ctx, inputPath, appendPath, outputPath, insertMs)
+			return err
+		})
+	})
+}
+
+// runTrackedByDuration probes inputPath's duration and runs op with a
+// context carrying sink, so any ffmpeg invocation op makes (directly or
+// transitively) reports progress against that duration.
+func runTrackedByDuration(ctx context.Context, inputPath string, sink ProgressSink, op func(ctx context.Context) error) error {
+	durationMs, err := probeDurationMs(ctx, inputPath)
+	if err != nil {
+		return err
+	}
+	return op(withProgressSink(ctx, sink, durationMs))
+}
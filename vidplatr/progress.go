@@ -0,0 +1,113 @@
+package vidplatr
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ========================= //
+//   ffmpeg progress parsing //
+// ========================= //
+
+type progressContextKey struct{}
+
+type progressContext struct {
+	sink            ProgressSink
+	totalDurationMs int64
+}
+
+// withProgressSink attaches sink to ctx so that any runFFmpeg call made
+// with the returned context (directly or by a function it calls) reports
+// fractional progress through sink, based on totalDurationMs.
+func withProgressSink(ctx context.Context, sink ProgressSink, totalDurationMs int64) context.Context {
+	if sink == nil || totalDurationMs <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, progressContextKey{}, progressContext{sink: sink, totalDurationMs: totalDurationMs})
+}
+
+func progressSinkFromContext(ctx context.Context) (ProgressSink, int64, bool) {
+	pc, ok := ctx.Value(progressContextKey{}).(progressContext)
+	if !ok {
+		return nil, 0, false
+	}
+	return pc.sink, pc.totalDurationMs, true
+}
+
+// runFFmpegWithProgress runs ffmpeg with args, additionally requesting
+// `-progress pipe:2 -nostats` so ffmpeg emits `key=value` progress lines on
+// stderr alongside its normal (loglevel=error) output. It parses
+// `out_time_ms=` lines, whose value ffmpeg reports in microseconds despite
+// the name, and reports out_time_ms/1000/totalDurationMs to sink as it goes.
+func runFFmpegWithProgress(ctx context.Context, sink ProgressSink, totalDurationMs int64, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg", withProgressArgs(args)...)
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return "", err
+	}
+	cmd.Stdout = nil
+
+	var output strings.Builder
+	if err := cmd.Start(); err != nil {
+		return "", errors.New("ffmpeg failed: " + err.Error())
+	}
+
+	scanner := bufio.NewScanner(stderr)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		output.WriteString(line)
+		output.WriteByte('\n')
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "out_time_ms":
+			outTimeMs, convErr := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if convErr == nil && totalDurationMs > 0 {
+				sink.SetProgress(float64(outTimeMs) / 1000 / float64(totalDurationMs))
+			}
+		case "progress":
+			if strings.TrimSpace(value) == "end" {
+				sink.SetProgress(1)
+			}
+		}
+	}
+
+	runErr := cmd.Wait()
+	out := strings.TrimSpace(output.String())
+	if runErr != nil {
+		if out == "" {
+			out = runErr.Error()
+		}
+		return out, errors.New("ffmpeg failed: " + out)
+	}
+	return out, nil
+}
+
+// withProgressArgs inserts `-progress pipe:2 -nostats` before the first
+// `-i` flag (ffmpeg treats these as global options, valid anywhere before
+// the output is named, but placing them up front keeps every caller's args
+// slice easy to reason about).
+func withProgressArgs(args []string) []string {
+	out := make([]string, 0, len(args)+3)
+	inserted := false
+	for _, a := range args {
+		if !inserted && a == "-i" {
+			out = append(out, "-progress", "pipe:2", "-nostats")
+			inserted = true
+		}
+		out = append(out, a)
+	}
+	if !inserted {
+		out = append(out, "-progress", "pipe:2", "-nostats")
+	}
+	return out
+}
@@ -0,0 +1,459 @@
+package vidplatr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ========================= //
+//  Smart Cut (GOP-aware)    //
+// ========================= //
+
+// CopySectionOptions controls optional behavior for CopySectionOpts.
+type CopySectionOptions struct {
+	// SmartCut enables GOP-aware cutting: only the head/tail slivers that
+	// straddle a keyframe boundary are re-encoded, and the (typically much
+	// larger) middle section between keyframes is stream-copied.
+	SmartCut bool
+
+	// EncoderProfile overrides DefaultEncoderProfile for the smart cut's
+	// head/tail re-encodes. Zero value means "use the package default".
+	// The plain CopySection path, its re-encode fallback, and the concat
+	// re-encode fallback always use DefaultEncoderProfile.
+	EncoderProfile EncoderProfile
+
+	// Selection, when non-zero, preserves the chosen streams (additional
+	// audio tracks, subtitles, chapters) instead of the package default
+	// first-video/first-audio layout. SmartCut is ignored when Selection is
+	// set: GOP-aware head/tail re-encoding only reasons about a single
+	// video+audio pair, so the cut falls back to a plain stream-aware copy
+	// (with re-encode fallback) instead.
+	Selection StreamSelection
+}
+
+// CopySectionOpts is CopySection with opt-in smart-cut behavior.
+//
+// When opts.SmartCut is set, it enumerates keyframe PTS in the source via
+// ffprobe and decomposes [startMs,endMs] into a re-encoded head (startMs to
+// the first keyframe >= startMs), a stream-copied middle (that keyframe to
+// the last keyframe <= endMs), and a re-encoded tail (that keyframe to
+// endMs), then stitches the three back together with the concat demuxer.
+// This avoids re-encoding the whole clip just to get accurate cut points.
+//
+// It silently falls back to the plain CopySection behavior when the smart
+// cut isn't profitable (a segment shorter than one GOP) or the source codec
+// isn't one the concat demuxer can stitch back together (libx264/aac).
+func CopySectionOpts(ctx context.Context, inputPath, outputPath string, startMs, endMs int64, opts CopySectionOptions) error {
+	if strings.TrimSpace(inputPath) == "" {
+		return errors.New("inputPath is empty")
+	}
+	if strings.TrimSpace(outputPath) == "" {
+		return errors.New("outputPath is empty")
+	}
+	if startMs < 0 {
+		return errors.New("startMs must be >= 0")
+	}
+	if endMs <= startMs {
+		return errors.New("endMs must be > startMs")
+	}
+
+	if !opts.SmartCut || !opts.Selection.isZero() {
+		return copySectionSelection(ctx, inputPath, outputPath, startMs, endMs, opts.Selection)
+	}
+
+	if err := ensureParentDir(outputPath); err != nil {
+		return err
+	}
+
+	profile := opts.EncoderProfile
+	if profile == "" {
+		profile = DefaultEncoderProfile()
+	}
+
+	ok, err := smartCutSection(ctx, inputPath, outputPath, startMs, endMs, profile)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+
+	return CopySection(ctx, inputPath, outputPath, startMs, endMs)
+}
+
+type sourceCodecParams struct {
+	videoCodec string
+	profile    string
+	pixFmt     string
+	timeBase   string
+	sar        string
+	audioCodec string
+}
+
+func probeSourceCodecParams(ctx context.Context, inputPath string) (sourceCodecParams, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_entries", "stream=codec_name,codec_type,profile,pix_fmt,time_base,sample_aspect_ratio",
+		"-print_format", "json",
+		inputPath,
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return sourceCodecParams{}, errors.New("ffprobe failed: " + msg)
+	}
+
+	var out struct {
+		Streams []struct {
+			CodecName         string `json:"codec_name"`
+			CodecType         string `json:"codec_type"`
+			Profile           string `json:"profile"`
+			PixFmt            string `json:"pix_fmt"`
+			TimeBase          string `json:"time_base"`
+			SampleAspectRatio string `json:"sample_aspect_ratio"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return sourceCodecParams{}, err
+	}
+
+	var params sourceCodecParams
+	for _, s := range out.Streams {
+		switch s.CodecType {
+		case "video":
+			params.videoCodec = s.CodecName
+			params.profile = s.Profile
+			params.pixFmt = s.PixFmt
+			params.timeBase = s.TimeBase
+			params.sar = s.SampleAspectRatio
+		case "audio":
+			params.audioCodec = s.CodecName
+		}
+	}
+	return params, nil
+}
+
+// parseTimeBaseTimescale extracts the denominator from an ffprobe time_base
+// string like "1/15360" for use as ffmpeg's -video_track_timescale, so a
+// reencoded head/tail keeps the source's mdhd timescale and concatenates
+// with the stream-copied middle without a timestamp rescale. ok is false for
+// an empty, malformed, or non-1-numerator time_base, since those aren't
+// timescales ffmpeg's flag can reproduce directly.
+func parseTimeBaseTimescale(timeBase string) (timescale int, ok bool) {
+	num, den, found := strings.Cut(timeBase, "/")
+	if !found || strings.TrimSpace(num) != "1" {
+		return 0, false
+	}
+	timescale, err := strconv.Atoi(strings.TrimSpace(den))
+	if err != nil || timescale <= 0 {
+		return 0, false
+	}
+	return timescale, true
+}
+
+func probeKeyframesMs(ctx context.Context, inputPath string) ([]int64, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-skip_frame", "nokey",
+		"-show_entries", "frame=pkt_pts_time,pict_type,key_frame",
+		"-print_format", "json",
+		inputPath,
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, errors.New("ffprobe failed: " + msg)
+	}
+
+	var out struct {
+		Frames []struct {
+			PktPtsTime string `json:"pkt_pts_time"`
+			KeyFrame   int    `json:"key_frame"`
+		} `json:"frames"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, err
+	}
+
+	keyframesMs := make([]int64, 0, len(out.Frames))
+	for _, f := range out.Frames {
+		if f.KeyFrame == 0 {
+			continue
+		}
+		secStr := strings.TrimSpace(f.PktPtsTime)
+		if secStr == "" {
+			continue
+		}
+		secs, err := strconv.ParseFloat(secStr, 64)
+		if err != nil {
+			continue
+		}
+		keyframesMs = append(keyframesMs, int64(secs*1000))
+	}
+	return keyframesMs, nil
+}
+
+// smartCutSection attempts the GOP-aware cut. ok is false (with a nil error)
+// when the cut isn't profitable, so the caller can fall back to the plain
+// CopySection behavior.
+func smartCutSection(ctx context.Context, inputPath, outputPath string, startMs, endMs int64, profile EncoderProfile) (ok bool, err error) {
+	dir := filepath.Dir(outputPath)
+	ext := filepath.Ext(outputPath)
+
+	parts, cleanup, ok, err := buildSmartCutParts(ctx, inputPath, dir, ext, startMs, endMs, profile)
+	defer cleanup()
+	if err != nil || !ok {
+		return false, err
+	}
+
+	listPath, listCleanup, err := createConcatListFile(dir, parts)
+	if err != nil {
+		return false, err
+	}
+	defer listCleanup()
+
+	if err := concatListCopyOrReencode(ctx, listPath, outputPath); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// buildSmartCutParts decomposes [startMs,endMs] of inputPath into the
+// GOP-aware re-encoded head, stream-copied middle, and re-encoded tail
+// smartCutSection stitches together, writing each part as a temp file under
+// workDir (named with ext) and returning their paths in stitch order. ok is
+// false (with a nil error) when the cut isn't profitable (a segment shorter
+// than one GOP) or the source codec isn't one the concat demuxer can stitch
+// back together (libx264/aac) — callers should fall back to a plain cut in
+// that case. cleanup removes any temp files already written, and must be
+// called (even when ok is false or err is non-nil) to avoid leaking them.
+func buildSmartCutParts(ctx context.Context, inputPath, workDir, ext string, startMs, endMs int64, profile EncoderProfile) (parts []string, cleanup func(), ok bool, err error) {
+	cleanup = func() {}
+
+	params, err := probeSourceCodecParams(ctx, inputPath)
+	if err != nil {
+		return nil, cleanup, false, err
+	}
+	if !isSmartCutCompatible(params) {
+		return nil, cleanup, false, nil
+	}
+
+	keyframesMs, err := probeKeyframesMs(ctx, inputPath)
+	if err != nil {
+		return nil, cleanup, false, err
+	}
+	if len(keyframesMs) < 2 {
+		return nil, cleanup, false, nil
+	}
+
+	headKeyMs, found := firstKeyframeAtOrAfter(keyframesMs, startMs)
+	if !found {
+		return nil, cleanup, false, nil
+	}
+	tailKeyMs, found := lastKeyframeAtOrBefore(keyframesMs, endMs)
+	if !found || tailKeyMs <= headKeyMs {
+		return nil, cleanup, false, nil
+	}
+
+	gopDurationMs := estimateGopDurationMs(keyframesMs)
+	headDurationMs := headKeyMs - startMs
+	tailDurationMs := endMs - tailKeyMs
+	if headDurationMs > 0 && headDurationMs < gopDurationMs {
+		return nil, cleanup, false, nil
+	}
+	if tailDurationMs > 0 && tailDurationMs < gopDurationMs {
+		return nil, cleanup, false, nil
+	}
+
+	mkTemp := func(prefix string) (string, error) {
+		f, err := os.CreateTemp(workDir, prefix+"-*"+ext)
+		if err != nil {
+			return "", err
+		}
+		name := f.Name()
+		_ = f.Close()
+		return name, nil
+	}
+
+	cleanup = func() {
+		for _, p := range parts {
+			_ = os.Remove(p)
+		}
+	}
+
+	if headDurationMs > 0 {
+		headPath, err := mkTemp("mvedit-smartcut-head")
+		if err != nil {
+			return parts, cleanup, false, err
+		}
+		parts = append(parts, headPath)
+		if err := reencodeMatchingSource(ctx, inputPath, headPath, startMs, headKeyMs, params, profile); err != nil {
+			return parts, cleanup, false, err
+		}
+	}
+
+	middlePath, err := mkTemp("mvedit-smartcut-mid")
+	if err != nil {
+		return parts, cleanup, false, err
+	}
+	parts = append(parts, middlePath)
+	if err := streamCopySection(ctx, inputPath, middlePath, headKeyMs, tailKeyMs); err != nil {
+		return parts, cleanup, false, err
+	}
+
+	if tailDurationMs > 0 {
+		tailPath, err := mkTemp("mvedit-smartcut-tail")
+		if err != nil {
+			return parts, cleanup, false, err
+		}
+		parts = append(parts, tailPath)
+		if err := reencodeMatchingSource(ctx, inputPath, tailPath, tailKeyMs, endMs, params, profile); err != nil {
+			return parts, cleanup, false, err
+		}
+	}
+
+	return parts, cleanup, true, nil
+}
+
+// isSmartCutCompatible reports whether the source codec is one our
+// libx264/aac head/tail re-encodes can be concatenated with losslessly.
+func isSmartCutCompatible(params sourceCodecParams) bool {
+	if params.videoCodec != "h264" {
+		return false
+	}
+	switch params.audioCodec {
+	case "", "aac":
+		return true
+	default:
+		return false
+	}
+}
+
+func firstKeyframeAtOrAfter(keyframesMs []int64, ms int64) (int64, bool) {
+	for _, k := range keyframesMs {
+		if k >= ms {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+func lastKeyframeAtOrBefore(keyframesMs []int64, ms int64) (int64, bool) {
+	found := false
+	var best int64
+	for _, k := range keyframesMs {
+		if k <= ms {
+			best = k
+			found = true
+		}
+	}
+	return best, found
+}
+
+func estimateGopDurationMs(keyframesMs []int64) int64 {
+	if len(keyframesMs) < 2 {
+		return 0
+	}
+	span := keyframesMs[len(keyframesMs)-1] - keyframesMs[0]
+	return span / int64(len(keyframesMs)-1)
+}
+
+func streamCopySection(ctx context.Context, inputPath, outputPath string, startMs, endMs int64) error {
+	startTimestamp := formatTimestampMs(startMs)
+	clipDuration := formatTimestampMs(endMs - startMs)
+
+	ffmpegArgs := []string{
+		"-y",
+		"-hide_banner",
+		"-loglevel", "error",
+		"-ss", startTimestamp,
+		"-t", clipDuration,
+		"-i", inputPath,
+		"-map", "0:v:0",
+		"-map", "0:a?",
+		"-c", "copy",
+		"-avoid_negative_ts", "make_zero",
+	}
+	ffmpegArgs = append(ffmpegArgs, movflags(outputPath)...)
+	ffmpegArgs = append(ffmpegArgs, outputPath)
+
+	_, err := runFFmpeg(ctx, ffmpegArgs...)
+	return err
+}
+
+// reencodeMatchingSource re-encodes [startMs,endMs] using codec parameters
+// probed from the source so the result can be concatenated with a
+// stream-copied middle segment via the concat demuxer.
+func reencodeMatchingSource(ctx context.Context, inputPath, outputPath string, startMs, endMs int64, params sourceCodecParams, profile EncoderProfile) error {
+	startTimestamp := formatTimestampMs(startMs)
+	clipDuration := formatTimestampMs(endMs - startMs)
+
+	buildArgs := func(profile EncoderProfile) []string {
+		hwaccelArgs, videoArgs := encoderFFmpegArgs(profile)
+
+		ffmpegArgs := []string{
+			"-y",
+			"-hide_banner",
+			"-loglevel", "error",
+		}
+		ffmpegArgs = append(ffmpegArgs, hwaccelArgs...)
+		ffmpegArgs = append(ffmpegArgs,
+			"-ss", startTimestamp,
+			"-t", clipDuration,
+			"-i", inputPath,
+			"-map", "0:v:0",
+			"-map", "0:a?",
+		)
+		ffmpegArgs = append(ffmpegArgs, videoArgs...)
+
+		if profile == SoftwareX264 {
+			// Match the source's profile/pix_fmt/SAR/timescale so the result
+			// can be losslessly concatenated with the stream-copied middle.
+			if params.profile != "" {
+				ffmpegArgs = append(ffmpegArgs, "-profile:v", params.profile)
+			}
+			pixFmt := params.pixFmt
+			if pixFmt == "" {
+				pixFmt = "yuv420p"
+			}
+			ffmpegArgs = append(ffmpegArgs, "-pix_fmt", pixFmt)
+			if params.sar != "" && params.sar != "0:1" {
+				ffmpegArgs = append(ffmpegArgs, "-vf", "setsar="+params.sar)
+			}
+			if timescale, ok := parseTimeBaseTimescale(params.timeBase); ok {
+				ffmpegArgs = append(ffmpegArgs, "-video_track_timescale", strconv.Itoa(timescale))
+			}
+		}
+
+		ffmpegArgs = append(ffmpegArgs, "-c:a", "aac", "-b:a", "192k")
+		ffmpegArgs = append(ffmpegArgs, movflags(outputPath)...)
+		ffmpegArgs = append(ffmpegArgs, outputPath)
+		return ffmpegArgs
+	}
+
+	_, err := runFFmpegReencode(ctx, profile, buildArgs)
+	return err
+}
@@ -0,0 +1,116 @@
+package vidplatr
+
+import "testing"
+
+func TestFirstKeyframeAtOrAfter(t *testing.T) {
+	keyframesMs := []int64{0, 2000, 4000, 6000}
+
+	tests := []struct {
+		ms        int64
+		wantMs    int64
+		wantFound bool
+	}{
+		{ms: 0, wantMs: 0, wantFound: true},
+		{ms: 1999, wantMs: 2000, wantFound: true},
+		{ms: 2000, wantMs: 2000, wantFound: true},
+		{ms: 6000, wantMs: 6000, wantFound: true},
+		{ms: 6001, wantMs: 0, wantFound: false},
+	}
+	for _, tt := range tests {
+		gotMs, gotFound := firstKeyframeAtOrAfter(keyframesMs, tt.ms)
+		if gotMs != tt.wantMs || gotFound != tt.wantFound {
+			t.Errorf("firstKeyframeAtOrAfter(%v, %d) = (%d, %v), want (%d, %v)",
+				keyframesMs, tt.ms, gotMs, gotFound, tt.wantMs, tt.wantFound)
+		}
+	}
+}
+
+func TestLastKeyframeAtOrBefore(t *testing.T) {
+	keyframesMs := []int64{0, 2000, 4000, 6000}
+
+	tests := []struct {
+		ms        int64
+		wantMs    int64
+		wantFound bool
+	}{
+		{ms: 0, wantMs: 0, wantFound: true},
+		{ms: 1999, wantMs: 0, wantFound: true},
+		{ms: 4000, wantMs: 4000, wantFound: true},
+		{ms: 6000, wantMs: 6000, wantFound: true},
+		{ms: -1, wantMs: 0, wantFound: false},
+	}
+	for _, tt := range tests {
+		gotMs, gotFound := lastKeyframeAtOrBefore(keyframesMs, tt.ms)
+		if gotMs != tt.wantMs || gotFound != tt.wantFound {
+			t.Errorf("lastKeyframeAtOrBefore(%v, %d) = (%d, %v), want (%d, %v)",
+				keyframesMs, tt.ms, gotMs, gotFound, tt.wantMs, tt.wantFound)
+		}
+	}
+}
+
+func TestEstimateGopDurationMs(t *testing.T) {
+	tests := []struct {
+		name        string
+		keyframesMs []int64
+		want        int64
+	}{
+		{name: "fewer than two keyframes", keyframesMs: []int64{0}, want: 0},
+		{name: "no keyframes", keyframesMs: nil, want: 0},
+		{name: "evenly spaced", keyframesMs: []int64{0, 2000, 4000, 6000}, want: 2000},
+		{name: "uneven spacing averages over the span", keyframesMs: []int64{0, 1000, 6000}, want: 3000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateGopDurationMs(tt.keyframesMs); got != tt.want {
+				t.Errorf("estimateGopDurationMs(%v) = %d, want %d", tt.keyframesMs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeBaseTimescale(t *testing.T) {
+	tests := []struct {
+		name          string
+		timeBase      string
+		wantTimescale int
+		wantOK        bool
+	}{
+		{name: "typical video time base", timeBase: "1/15360", wantTimescale: 15360, wantOK: true},
+		{name: "empty", timeBase: "", wantOK: false},
+		{name: "non-1 numerator", timeBase: "2/15360", wantOK: false},
+		{name: "no slash", timeBase: "15360", wantOK: false},
+		{name: "zero denominator", timeBase: "1/0", wantOK: false},
+		{name: "non-numeric denominator", timeBase: "1/abc", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotTimescale, gotOK := parseTimeBaseTimescale(tt.timeBase)
+			if gotOK != tt.wantOK {
+				t.Fatalf("parseTimeBaseTimescale(%q) ok = %v, want %v", tt.timeBase, gotOK, tt.wantOK)
+			}
+			if gotOK && gotTimescale != tt.wantTimescale {
+				t.Errorf("parseTimeBaseTimescale(%q) = %d, want %d", tt.timeBase, gotTimescale, tt.wantTimescale)
+			}
+		})
+	}
+}
+
+func TestIsSmartCutCompatible(t *testing.T) {
+	tests := []struct {
+		name   string
+		params sourceCodecParams
+		want   bool
+	}{
+		{name: "h264+aac", params: sourceCodecParams{videoCodec: "h264", audioCodec: "aac"}, want: true},
+		{name: "h264 with no audio track", params: sourceCodecParams{videoCodec: "h264", audioCodec: ""}, want: true},
+		{name: "h264+mp3", params: sourceCodecParams{videoCodec: "h264", audioCodec: "mp3"}, want: false},
+		{name: "hevc", params: sourceCodecParams{videoCodec: "hevc", audioCodec: "aac"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSmartCutCompatible(tt.params); got != tt.want {
+				t.Errorf("isSmartCutCompatible(%+v) = %v, want %v", tt.params, got, tt.want)
+			}
+		})
+	}
+}
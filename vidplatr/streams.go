@@ -0,0 +1,429 @@
+package vidplatr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ========================= //
+//   Multi-stream selection  //
+// ========================= //
+
+// StreamType is the ffprobe codec_type of a stream.
+type StreamType string
+
+const (
+	StreamVideo    StreamType = "video"
+	StreamAudio    StreamType = "audio"
+	StreamSubtitle StreamType = "subtitle"
+)
+
+// StreamInfo is one entry from ProbeStreams.
+type StreamInfo struct {
+	Index     int
+	CodecType StreamType
+	CodecName string
+	Language  string
+	Title     string
+}
+
+// StreamSpec names a stream (or a class of streams) within Include/Exclude.
+// Empty Language/CodecType are wildcards. Index is a wildcard only when
+// negative: the zero value 0 matches stream 0 alone, so a spec naming
+// streams by language or codec type alone sets Index to -1.
+type StreamSpec struct {
+	Index     int
+	Language  string
+	CodecType StreamType
+}
+
+func (spec StreamSpec) matches(s StreamInfo) bool {
+	if spec.Index >= 0 && spec.Index != s.Index {
+		return false
+	}
+	if spec.Language != "" && !strings.EqualFold(spec.Language, s.Language) {
+		return false
+	}
+	if spec.CodecType != "" && spec.CodecType != s.CodecType {
+		return false
+	}
+	return true
+}
+
+// StreamSelection chooses which of a source's streams an edit op maps into
+// its output. The zero value reproduces this package's long-standing
+// default: first video stream plus every audio stream, if any
+// (`-map 0:v:0 -map 0:a?`).
+type StreamSelection struct {
+	AllVideo     bool
+	AllAudio     bool
+	AllSubtitles bool
+	Chapters     bool
+	Include      []StreamSpec
+	Exclude      []StreamSpec
+}
+
+func (sel StreamSelection) isZero() bool {
+	return !sel.AllVideo && !sel.AllAudio && !sel.AllSubtitles && !sel.Chapters &&
+		len(sel.Include) == 0 && len(sel.Exclude) == 0
+}
+
+// ProbeStreams runs ffprobe against path and returns its streams.
+func ProbeStreams(ctx context.Context, path string) ([]StreamInfo, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, errors.New("path is empty")
+	}
+
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_entries", "stream=index,codec_type,codec_name:stream_tags=language,title",
+		"-print_format", "json",
+		path,
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, errors.New("ffprobe failed: " + msg)
+	}
+
+	var out struct {
+		Streams []struct {
+			Index     int    `json:"index"`
+			CodecType string `json:"codec_type"`
+			CodecName string `json:"codec_name"`
+			Tags      struct {
+				Language string `json:"language"`
+				Title    string `json:"title"`
+			} `json:"tags"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, err
+	}
+
+	streams := make([]StreamInfo, 0, len(out.Streams))
+	for _, s := range out.Streams {
+		streams = append(streams, StreamInfo{
+			Index:     s.Index,
+			CodecType: StreamType(s.CodecType),
+			CodecName: s.CodecName,
+			Language:  s.Tags.Language,
+			Title:     s.Tags.Title,
+		})
+	}
+	return streams, nil
+}
+
+func matchSelectedStreams(selection StreamSelection, streams []StreamInfo) []StreamInfo {
+	var matched []StreamInfo
+	for _, s := range streams {
+		include := false
+		switch s.CodecType {
+		case StreamVideo:
+			include = selection.AllVideo
+		case StreamAudio:
+			include = selection.AllAudio
+		case StreamSubtitle:
+			include = selection.AllSubtitles
+		}
+		for _, spec := range selection.Include {
+			if spec.matches(s) {
+				include = true
+				break
+			}
+		}
+		if include {
+			for _, spec := range selection.Exclude {
+				if spec.matches(s) {
+					include = false
+					break
+				}
+			}
+		}
+		if include {
+			matched = append(matched, s)
+		}
+	}
+	return matched
+}
+
+// buildStreamMapArgs emits one -map per stream selected by selection, plus
+// -map_metadata/-map_chapters and per-stream language/title metadata, so
+// the command builder can keep subtitle tracks (which a bare
+// `-map 0:v:0 -map 0:a?` drops) and choose exactly which audio tracks are
+// kept.
+func buildStreamMapArgs(selection StreamSelection, streams []StreamInfo) []string {
+	if selection.isZero() {
+		return []string{"-map", "0:v:0", "-map", "0:a?"}
+	}
+
+	matched := matchSelectedStreams(selection, streams)
+
+	args := []string{"-map_metadata", "0"}
+	audioN, subN := 0, 0
+	for _, s := range matched {
+		args = append(args, "-map", fmt.Sprintf("0:%d", s.Index))
+
+		switch s.CodecType {
+		case StreamAudio:
+			if s.Language != "" {
+				args = append(args, fmt.Sprintf("-metadata:s:a:%d", audioN), "language="+s.Language)
+			}
+			if s.Title != "" {
+				args = append(args, fmt.Sprintf("-metadata:s:a:%d", audioN), "title="+s.Title)
+			}
+			audioN++
+		case StreamSubtitle:
+			if s.Language != "" {
+				args = append(args, fmt.Sprintf("-metadata:s:s:%d", subN), "language="+s.Language)
+			}
+			subN++
+		}
+	}
+	if selection.Chapters {
+		args = append(args, "-map_chapters", "0")
+	}
+	return args
+}
+
+// copySectionSelection is CopySection with an explicit StreamSelection. A
+// zero-value selection delegates to CopySection so callers that never
+// touch multi-track content pay no extra probing cost.
+//
+// It mirrors CopySection's two-attempt strategy: a fast stream copy,
+// falling back to a re-encode for accurate cuts.
+func copySectionSelection(ctx context.Context, inputPath, outputPath string, startMs, endMs int64, selection StreamSelection) error {
+	if selection.isZero() {
+		return CopySection(ctx, inputPath, outputPath, startMs, endMs)
+	}
+	if err := ensureParentDir(outputPath); err != nil {
+		return err
+	}
+
+	streams, err := ProbeStreams(ctx, inputPath)
+	if err != nil {
+		return err
+	}
+	mapArgs := buildStreamMapArgs(selection, streams)
+
+	startTimestamp := formatTimestampMs(startMs)
+	clipDuration := formatTimestampMs(endMs - startMs)
+
+	ffmpegCopyArgs := []string{
+		"-y",
+		"-hide_banner",
+		"-loglevel", "error",
+		"-ss", startTimestamp,
+		"-t", clipDuration,
+		"-i", inputPath,
+	}
+	ffmpegCopyArgs = append(ffmpegCopyArgs, mapArgs...)
+	ffmpegCopyArgs = append(ffmpegCopyArgs, "-c", "copy", "-avoid_negative_ts", "make_zero")
+	ffmpegCopyArgs = append(ffmpegCopyArgs, movflags(outputPath)...)
+	ffmpegCopyArgs = append(ffmpegCopyArgs, outputPath)
+
+	copyErrOutput, copyErr := runFFmpeg(ctx, ffmpegCopyArgs...)
+	if copyErr == nil {
+		return nil
+	}
+
+	buildReencodeArgs := func(profile EncoderProfile) []string {
+		hwaccelArgs, videoArgs := encoderFFmpegArgs(profile)
+		ffmpegReencodeArgs := []string{
+			"-y",
+			"-hide_banner",
+			"-loglevel", "error",
+		}
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, hwaccelArgs...)
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, "-ss", startTimestamp, "-t", clipDuration, "-i", inputPath)
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, mapArgs...)
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, videoArgs...)
+		if profile == SoftwareX264 {
+			ffmpegReencodeArgs = append(ffmpegReencodeArgs, "-pix_fmt", "yuv420p")
+		}
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, "-c:a", "aac", "-b:a", "192k", "-c:s", "copy")
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, movflags(outputPath)...)
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, outputPath)
+		return ffmpegReencodeArgs
+	}
+
+	reencodeErrOutput, reencodeErr := runFFmpegReencode(ctx, DefaultEncoderProfile(), buildReencodeArgs)
+	if reencodeErr != nil {
+		return errors.New(strings.TrimSpace("stream-copy failed: " + copyErrOutput + "\nre-encode failed: " + reencodeErrOutput))
+	}
+	return nil
+}
+
+// concatMapArgs builds the -map args a selection-aware concat should use,
+// probed from the first (representative) path. The concat demuxer requires
+// every input to share the same stream layout, so probing one path is
+// sufficient; nil means "use the package default video+audio maps".
+func concatMapArgs(ctx context.Context, selection StreamSelection, representativePath string) ([]string, error) {
+	if selection.isZero() {
+		return nil, nil
+	}
+	streams, err := ProbeStreams(ctx, representativePath)
+	if err != nil {
+		return nil, err
+	}
+	return buildStreamMapArgs(selection, streams), nil
+}
+
+// CopyFileOpts is CopyFile with an explicit StreamSelection instead of the
+// package default (first video + every audio track, `-map 0:v:0 -map 0:a?`).
+func CopyFileOpts(ctx context.Context, inputPath, outputPath string, selection StreamSelection) error {
+	if strings.TrimSpace(inputPath) == "" {
+		return errors.New("inputPath is empty")
+	}
+	if strings.TrimSpace(outputPath) == "" {
+		return errors.New("outputPath is empty")
+	}
+	if err := ensureParentDir(outputPath); err != nil {
+		return err
+	}
+
+	streams, err := ProbeStreams(ctx, inputPath)
+	if err != nil {
+		return err
+	}
+
+	ffmpegArgs := []string{
+		"-y",
+		"-hide_banner",
+		"-loglevel", "error",
+		"-i", inputPath,
+	}
+	ffmpegArgs = append(ffmpegArgs, buildStreamMapArgs(selection, streams)...)
+	ffmpegArgs = append(ffmpegArgs, "-c", "copy")
+	ffmpegArgs = append(ffmpegArgs, movflags(outputPath)...)
+	ffmpegArgs = append(ffmpegArgs, outputPath)
+
+	_, err = runFFmpeg(ctx, ffmpegArgs...)
+	return err
+}
+
+// audioParams is the subset of audio stream parameters the concat demuxer
+// requires to match exactly across every input.
+type audioParams struct {
+	codecName  string
+	sampleRate string
+	channels   string
+}
+
+func probeAudioParams(ctx context.Context, path string) (params audioParams, hasAudio bool, err error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-select_streams", "a:0",
+		"-show_entries", "stream=codec_name,sample_rate,channels",
+		"-print_format", "json",
+		path,
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return audioParams{}, false, errors.New("ffprobe failed: " + msg)
+	}
+
+	var out struct {
+		Streams []struct {
+			CodecName  string `json:"codec_name"`
+			SampleRate string `json:"sample_rate"`
+			Channels   int    `json:"channels"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return audioParams{}, false, err
+	}
+	if len(out.Streams) == 0 {
+		return audioParams{}, false, nil
+	}
+
+	s := out.Streams[0]
+	return audioParams{
+		codecName:  s.CodecName,
+		sampleRate: s.SampleRate,
+		channels:   strconv.Itoa(s.Channels),
+	}, true, nil
+}
+
+// selectedAudioStreamIndexes returns the absolute ffprobe stream indexes of
+// the audio streams selection maps into the output, in the same order
+// buildStreamMapArgs emits their -map args. Used so normalizeConcatAudio can
+// check/normalize every audio track a multi-track StreamSelection selects,
+// not just the first.
+func selectedAudioStreamIndexes(selection StreamSelection, streams []StreamInfo) []int {
+	var indexes []int
+	for _, s := range matchSelectedStreams(selection, streams) {
+		if s.CodecType == StreamAudio {
+			indexes = append(indexes, s.Index)
+		}
+	}
+	return indexes
+}
+
+// probeAudioParamsAt probes the audio stream at streamIndex — an absolute
+// ffprobe stream index, as returned by ProbeStreams/selectedAudioStreamIndexes
+// — instead of always a:0, so a mismatched second (e.g. commentary) track
+// isn't missed just because it isn't the first audio stream.
+func probeAudioParamsAt(ctx context.Context, path string, streamIndex int) (audioParams, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-select_streams", strconv.Itoa(streamIndex),
+		"-show_entries", "stream=codec_name,sample_rate,channels",
+		"-print_format", "json",
+		path,
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return audioParams{}, errors.New("ffprobe failed: " + msg)
+	}
+
+	var out struct {
+		Streams []struct {
+			CodecName  string `json:"codec_name"`
+			SampleRate string `json:"sample_rate"`
+			Channels   int    `json:"channels"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return audioParams{}, err
+	}
+	if len(out.Streams) == 0 {
+		return audioParams{}, fmt.Errorf("ffprobe: no stream at index %d in %s", streamIndex, path)
+	}
+
+	s := out.Streams[0]
+	return audioParams{
+		codecName:  s.CodecName,
+		sampleRate: s.SampleRate,
+		channels:   strconv.Itoa(s.Channels),
+	}, nil
+}
@@ -0,0 +1,231 @@
+package vidplatr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ========================= //
+//  Thumbnail Sprites / VTT  //
+// ========================= //
+
+// maxSpriteRows bounds how tall a single sprite sheet gets before
+// GenerateThumbnails starts a new one.
+const maxSpriteRows = 10
+
+// ThumbnailOptions controls sprite/scrub-strip generation via
+// GenerateThumbnails.
+type ThumbnailOptions struct {
+	IntervalMs  int64
+	Width       int
+	Height      int
+	Columns     int
+	OutputDir   string
+	SpriteName  string
+	JPEGQuality int
+
+	// EncoderProfile, if set, is used for its hwaccel args so the decode
+	// side of thumbnail extraction can be GPU-assisted on large files.
+	// Zero value means DefaultEncoderProfile.
+	EncoderProfile EncoderProfile
+}
+
+// ThumbnailIndex describes the sprite sheet(s) and WebVTT sidecar produced
+// by GenerateThumbnails.
+type ThumbnailIndex struct {
+	SpritePaths []string
+	VTTPath     string
+	Columns     int
+	Width       int
+	Height      int
+	Count       int
+}
+
+// GenerateThumbnails probes inputPath's duration, samples a frame every
+// opts.IntervalMs, and tiles them into one or more sprite JPEGs (splitting
+// into additional sprites once a sheet would exceed maxSpriteRows rows), plus
+// a WebVTT sidecar mapping each time range to its tile's xywh within its
+// sprite.
+func GenerateThumbnails(ctx context.Context, inputPath string, opts ThumbnailOptions) (*ThumbnailIndex, error) {
+	if strings.TrimSpace(inputPath) == "" {
+		return nil, errors.New("inputPath is empty")
+	}
+	if opts.IntervalMs <= 0 {
+		return nil, errors.New("opts.IntervalMs must be > 0")
+	}
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return nil, errors.New("opts.Width and opts.Height must be > 0")
+	}
+	if opts.Columns <= 0 {
+		return nil, errors.New("opts.Columns must be > 0")
+	}
+	if strings.TrimSpace(opts.OutputDir) == "" {
+		return nil, errors.New("opts.OutputDir is empty")
+	}
+	spriteName := opts.SpriteName
+	if spriteName == "" {
+		spriteName = "sprite"
+	}
+	if err := os.MkdirAll(opts.OutputDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	durationMs, err := probeDurationMs(ctx, inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	count := int((durationMs + opts.IntervalMs - 1) / opts.IntervalMs)
+	if count <= 0 {
+		return nil, errors.New("video is shorter than one thumbnail interval")
+	}
+
+	capacityPerSprite := opts.Columns * maxSpriteRows
+	numSprites := (count + capacityPerSprite - 1) / capacityPerSprite
+
+	profile := opts.EncoderProfile
+	if profile == "" {
+		profile = DefaultEncoderProfile()
+	}
+
+	index := &ThumbnailIndex{Columns: opts.Columns, Width: opts.Width, Height: opts.Height, Count: count}
+
+	for spriteIdx := 0; spriteIdx < numSprites; spriteIdx++ {
+		startTile := spriteIdx * capacityPerSprite
+		tilesInSprite := count - startTile
+		if tilesInSprite > capacityPerSprite {
+			tilesInSprite = capacityPerSprite
+		}
+		rows := (tilesInSprite + opts.Columns - 1) / opts.Columns
+
+		spritePath := filepath.Join(opts.OutputDir, spriteName+strconv.Itoa(spriteIdx)+".jpg")
+		startMs := int64(startTile) * opts.IntervalMs
+
+		if err := generateSpriteSheet(ctx, inputPath, spritePath, startMs, opts, rows, profile); err != nil {
+			return nil, err
+		}
+		index.SpritePaths = append(index.SpritePaths, spritePath)
+	}
+
+	vttPath := filepath.Join(opts.OutputDir, spriteName+".vtt")
+	if err := writeThumbnailVTT(vttPath, durationMs, capacityPerSprite, spriteName, opts, count); err != nil {
+		return nil, err
+	}
+	index.VTTPath = vttPath
+
+	return index, nil
+}
+
+func generateSpriteSheet(ctx context.Context, inputPath, spritePath string, startMs int64, opts ThumbnailOptions, rows int, profile EncoderProfile) error {
+	intervalSec := strconv.FormatFloat(float64(opts.IntervalMs)/1000, 'f', -1, 64)
+	vf := "fps=1/" + intervalSec +
+		",scale=" + strconv.Itoa(opts.Width) + ":" + strconv.Itoa(opts.Height) +
+		",tile=" + strconv.Itoa(opts.Columns) + "x" + strconv.Itoa(rows)
+
+	quality := opts.JPEGQuality
+	if quality <= 0 {
+		quality = 2
+	}
+
+	hwaccelArgs, _ := encoderFFmpegArgs(profile)
+
+	ffmpegArgs := []string{
+		"-y",
+		"-hide_banner",
+		"-loglevel", "error",
+	}
+	ffmpegArgs = append(ffmpegArgs, hwaccelArgs...)
+	ffmpegArgs = append(ffmpegArgs,
+		"-ss", formatTimestampMs(startMs),
+		"-i", inputPath,
+		"-frames:v", "1",
+		"-vf", vf,
+		"-q:v", strconv.Itoa(quality),
+		spritePath,
+	)
+
+	_, err := runFFmpeg(ctx, ffmpegArgs...)
+	return err
+}
+
+// GenerateThumbnailAt extracts a single frame at atMs, scaled to w x h.
+func GenerateThumbnailAt(ctx context.Context, inputPath string, atMs int64, w, h int, outPath string) error {
+	if strings.TrimSpace(inputPath) == "" {
+		return errors.New("inputPath is empty")
+	}
+	if atMs < 0 {
+		return errors.New("atMs must be >= 0")
+	}
+	if w <= 0 || h <= 0 {
+		return errors.New("w and h must be > 0")
+	}
+	if strings.TrimSpace(outPath) == "" {
+		return errors.New("outPath is empty")
+	}
+	if err := ensureParentDir(outPath); err != nil {
+		return err
+	}
+
+	hwaccelArgs, _ := encoderFFmpegArgs(DefaultEncoderProfile())
+
+	ffmpegArgs := []string{
+		"-y",
+		"-hide_banner",
+		"-loglevel", "error",
+	}
+	ffmpegArgs = append(ffmpegArgs, hwaccelArgs...)
+	ffmpegArgs = append(ffmpegArgs,
+		"-ss", formatTimestampMs(atMs),
+		"-i", inputPath,
+		"-frames:v", "1",
+		"-vf", "scale="+strconv.Itoa(w)+":"+strconv.Itoa(h),
+		outPath,
+	)
+
+	_, err := runFFmpeg(ctx, ffmpegArgs...)
+	return err
+}
+
+// writeThumbnailVTT emits a WebVTT sidecar mapping each [i*interval,
+// (i+1)*interval) range to its tile's xywh within its sprite sheet.
+func writeThumbnailVTT(vttPath string, durationMs int64, capacityPerSprite int, spriteName string, opts ThumbnailOptions, count int) error {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+
+	for i := 0; i < count; i++ {
+		startMs := int64(i) * opts.IntervalMs
+		endMs := startMs + opts.IntervalMs
+		if endMs > durationMs {
+			endMs = durationMs
+		}
+
+		spriteIdx := i / capacityPerSprite
+		posInSprite := i % capacityPerSprite
+		col := posInSprite % opts.Columns
+		row := posInSprite / opts.Columns
+		x := col * opts.Width
+		y := row * opts.Height
+
+		b.WriteString(formatTimestampMs(startMs))
+		b.WriteString(" --> ")
+		b.WriteString(formatTimestampMs(endMs))
+		b.WriteString("\n")
+		b.WriteString(spriteName)
+		b.WriteString(strconv.Itoa(spriteIdx))
+		b.WriteString(".jpg#xywh=")
+		b.WriteString(strconv.Itoa(x))
+		b.WriteString(",")
+		b.WriteString(strconv.Itoa(y))
+		b.WriteString(",")
+		b.WriteString(strconv.Itoa(opts.Width))
+		b.WriteString(",")
+		b.WriteString(strconv.Itoa(opts.Height))
+		b.WriteString("\n\n")
+	}
+
+	return os.WriteFile(vttPath, []byte(b.String()), 0o644)
+}
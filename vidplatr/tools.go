@@ -93,6 +93,10 @@ func CopyFile(ctx context.Context, inputPath, outputPath string) error {
 // cut may only be exact on keyframes. If that fails, it falls back to re-encoding
 // for accuracy.
 func CopySection(ctx context.Context, inputPath, outputPath string, startMs, endMs int64) error {
+	return copySection(ctx, inputPath, outputPath, startMs, endMs)
+}
+
+func copySection(ctx context.Context, inputPath, outputPath string, startMs, endMs int64) error {
 	if strings.TrimSpace(inputPath) == "" {
 		return errors.New("inputPath is empty")
 	}
@@ -134,26 +138,32 @@ func CopySection(ctx context.Context, inputPath, outputPath string, startMs, end
 	}
 
 	// Attempt 2: re-encode fallback (accurate cuts).
-	ffmpegReencodeArgs := []string{
-		"-y",
-		"-hide_banner",
-		"-loglevel", "error",
-		"-ss", startTimestamp,
-		"-t", clipDuration,
-		"-i", inputPath,
-		"-map", "0:v:0",
-		"-map", "0:a?",
-		"-c:v", "libx264",
-		"-preset", "veryfast",
-		"-crf", "20",
-		"-pix_fmt", "yuv420p",
-		"-c:a", "aac",
-		"-b:a", "192k",
+	buildReencodeArgs := func(profile EncoderProfile) []string {
+		hwaccelArgs, videoArgs := encoderFFmpegArgs(profile)
+		ffmpegReencodeArgs := []string{
+			"-y",
+			"-hide_banner",
+			"-loglevel", "error",
+		}
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, hwaccelArgs...)
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs,
+			"-ss", startTimestamp,
+			"-t", clipDuration,
+			"-i", inputPath,
+			"-map", "0:v:0",
+			"-map", "0:a?",
+		)
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, videoArgs...)
+		if profile == SoftwareX264 {
+			ffmpegReencodeArgs = append(ffmpegReencodeArgs, "-pix_fmt", "yuv420p")
+		}
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, "-c:a", "aac", "-b:a", "192k")
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, movflags(outputPath)...)
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, outputPath)
+		return ffmpegReencodeArgs
 	}
-	ffmpegReencodeArgs = append(ffmpegReencodeArgs, movflags(outputPath)...)
-	ffmpegReencodeArgs = append(ffmpegReencodeArgs, outputPath)
 
-	reencodeErrOutput, reencodeErr := runFFmpeg(ctx, ffmpegReencodeArgs...)
+	reencodeErrOutput, reencodeErr := runFFmpegReencode(ctx, DefaultEncoderProfile(), buildReencodeArgs)
 	if reencodeErr != nil {
 		return errors.New(strings.TrimSpace("stream-copy failed: " + copyErrOutput + "\nre-encode failed: " + reencodeErrOutput))
 	}
@@ -173,6 +183,16 @@ func CopySection(ctx context.Context, inputPath, outputPath string, startMs, end
 //
 // It produces a new file that contains everything EXCEPT the deleted range.
 func DeleteSection(ctx context.Context, inputPath, outputPath string, startMs, endMs int64) error {
+	return deleteSection(ctx, inputPath, outputPath, startMs, endMs, StreamSelection{})
+}
+
+// DeleteSectionOpts is DeleteSection with an explicit StreamSelection, so
+// second audio tracks, subtitles, and chapters survive the cut.
+func DeleteSectionOpts(ctx context.Context, inputPath, outputPath string, startMs, endMs int64, selection StreamSelection) error {
+	return deleteSection(ctx, inputPath, outputPath, startMs, endMs, selection)
+}
+
+func deleteSection(ctx context.Context, inputPath, outputPath string, startMs, endMs int64, selection StreamSelection) error {
 	if strings.TrimSpace(inputPath) == "" {
 		return errors.New("inputPath is empty")
 	}
@@ -226,7 +246,7 @@ func DeleteSection(ctx context.Context, inputPath, outputPath string, startMs, e
 			return err
 		}
 		defer os.Remove(partA)
-		if err := CopySection(ctx, inputPath, partA, 0, startMs); err != nil {
+		if err := copySectionSelection(ctx, inputPath, partA, 0, startMs, selection); err != nil {
 			return err
 		}
 	}
@@ -238,7 +258,7 @@ func DeleteSection(ctx context.Context, inputPath, outputPath string, startMs, e
 			return err
 		}
 		defer os.Remove(partB)
-		if err := CopySection(ctx, inputPath, partB, endMs, durationMs); err != nil {
+		if err := copySectionSelection(ctx, inputPath, partB, endMs, durationMs, selection); err != nil {
 			return err
 		}
 	}
@@ -254,19 +274,13 @@ func DeleteSection(ctx context.Context, inputPath, outputPath string, startMs, e
 		return os.Rename(partA, outputPath)
 	}
 
-	listFile, listCleanup, err := createConcatListFile(dir, []string{partA, partB})
-	if err != nil {
-		return err
-	}
-	defer listCleanup()
-
 	outTmp, err := tempLike(outputPath, "mvedit-joined")
 	if err != nil {
 		return err
 	}
 	defer os.Remove(outTmp)
 
-	if err := concatListCopyOrReencode(ctx, listFile, outTmp); err != nil {
+	if err := concatPathsSelection(ctx, dir, []string{partA, partB}, outTmp, selection); err != nil {
 		return err
 	}
 
@@ -281,6 +295,16 @@ func DeleteSection(ctx context.Context, inputPath, outputPath string, startMs, e
 //
 // Returns the timestamp (ms) of the new final length.
 func AppendFile(ctx context.Context, inputPath, appendPath, outputPath string) (int64, error) {
+	return appendFile(ctx, inputPath, appendPath, outputPath, StreamSelection{})
+}
+
+// AppendFileOpts is AppendFile with an explicit StreamSelection, so
+// second audio tracks, subtitles, and chapters survive the join.
+func AppendFileOpts(ctx context.Context, inputPath, appendPath, outputPath string, selection StreamSelection) (int64, error) {
+	return appendFile(ctx, inputPath, appendPath, outputPath, selection)
+}
+
+func appendFile(ctx context.Context, inputPath, appendPath, outputPath string, selection StreamSelection) (int64, error) {
 	if strings.TrimSpace(inputPath) == "" {
 		return 0, errors.New("inputPath is empty")
 	}
@@ -300,13 +324,7 @@ func AppendFile(ctx context.Context, inputPath, appendPath, outputPath string) (
 	}
 	defer cleanup()
 
-	listPath, listCleanup, err := createConcatListFile(filepath.Dir(outPath), []string{inputPath, appendPath})
-	if err != nil {
-		return 0, err
-	}
-	defer listCleanup()
-
-	if err := concatListCopyOrReencode(ctx, listPath, outPath); err != nil {
+	if err := concatPathsSelection(ctx, filepath.Dir(outPath), []string{inputPath, appendPath}, outPath, selection); err != nil {
 		return 0, err
 	}
 	if err := finalize(); err != nil {
@@ -332,6 +350,16 @@ func AppendFile(ctx context.Context, inputPath, appendPath, outputPath string) (
 //
 // Returns the timestamp (ms) where the new appended section ends.
 func AppendSection(ctx context.Context, inputPath, appendPath, outputPath string, insertMs int64) (int64, error) {
+	return appendSection(ctx, inputPath, appendPath, outputPath, insertMs, StreamSelection{})
+}
+
+// AppendSectionOpts is AppendSection with an explicit StreamSelection, so
+// second audio tracks, subtitles, and chapters survive the insert.
+func AppendSectionOpts(ctx context.Context, inputPath, appendPath, outputPath string, insertMs int64, selection StreamSelection) (int64, error) {
+	return appendSection(ctx, inputPath, appendPath, outputPath, insertMs, selection)
+}
+
+func appendSection(ctx context.Context, inputPath, appendPath, outputPath string, insertMs int64, selection StreamSelection) (int64, error) {
 	if strings.TrimSpace(inputPath) == "" {
 		return 0, errors.New("inputPath is empty")
 	}
@@ -363,7 +391,7 @@ func AppendSection(ctx context.Context, inputPath, appendPath, outputPath string
 
 	// Prepend shortcut.
 	if insertMs == 0 {
-		if _, err := AppendFile(ctx, appendPath, inputPath, outputPath); err != nil {
+		if _, err := appendFile(ctx, appendPath, inputPath, outputPath, selection); err != nil {
 			return 0, err
 		}
 		return appendDurationMs, nil
@@ -371,7 +399,7 @@ func AppendSection(ctx context.Context, inputPath, appendPath, outputPath string
 
 	// Append shortcut.
 	if insertMs == inputDurationMs {
-		newFinalMs, err := AppendFile(ctx, inputPath, appendPath, outputPath)
+		newFinalMs, err := appendFile(ctx, inputPath, appendPath, outputPath, selection)
 		if err != nil {
 			return 0, err
 		}
@@ -403,10 +431,10 @@ func AppendSection(ctx context.Context, inputPath, appendPath, outputPath string
 	}
 	defer os.Remove(partB)
 
-	if err := CopySection(ctx, inputPath, partA, 0, insertMs); err != nil {
+	if err := copySectionSelection(ctx, inputPath, partA, 0, insertMs, selection); err != nil {
 		return 0, err
 	}
-	if err := CopySection(ctx, inputPath, partB, insertMs, inputDurationMs); err != nil {
+	if err := copySectionSelection(ctx, inputPath, partB, insertMs, inputDurationMs, selection); err != nil {
 		return 0, err
 	}
 
@@ -416,13 +444,7 @@ func AppendSection(ctx context.Context, inputPath, appendPath, outputPath string
 	}
 	newSectionEndsMs := partADurationMs + appendDurationMs
 
-	listPath, listCleanup, err := createConcatListFile(dir, []string{partA, appendPath, partB})
-	if err != nil {
-		return 0, err
-	}
-	defer listCleanup()
-
-	if err := concatListCopyOrReencode(ctx, listPath, outPath); err != nil {
+	if err := concatPathsSelection(ctx, dir, []string{partA, appendPath, partB}, outPath, selection); err != nil {
 		return 0, err
 	}
 
@@ -449,6 +471,16 @@ func AppendSection(ctx context.Context, inputPath, appendPath, outputPath string
 //
 // Returns the new timestamp (ms) that corresponds to the original endMs.
 func ReplaceSection(ctx context.Context, inputPath, replacePath, outputPath string, startMs, endMs int64) (int64, error) {
+	return replaceSection(ctx, inputPath, replacePath, outputPath, startMs, endMs, StreamSelection{})
+}
+
+// ReplaceSectionOpts is ReplaceSection with an explicit StreamSelection,
+// so second audio tracks, subtitles, and chapters survive the replace.
+func ReplaceSectionOpts(ctx context.Context, inputPath, replacePath, outputPath string, startMs, endMs int64, selection StreamSelection) (int64, error) {
+	return replaceSection(ctx, inputPath, replacePath, outputPath, startMs, endMs, selection)
+}
+
+func replaceSection(ctx context.Context, inputPath, replacePath, outputPath string, startMs, endMs int64, selection StreamSelection) (int64, error) {
 	if strings.TrimSpace(inputPath) == "" {
 		return 0, errors.New("inputPath is empty")
 	}
@@ -486,7 +518,11 @@ func ReplaceSection(ctx context.Context, inputPath, replacePath, outputPath stri
 
 	// Whole-file replace.
 	if startMs == 0 && endMs >= inputDurationMs {
-		if err := CopyFile(ctx, replacePath, outputPath); err != nil {
+		if selection.isZero() {
+			if err := CopyFile(ctx, replacePath, outputPath); err != nil {
+				return 0, err
+			}
+		} else if err := CopyFileOpts(ctx, replacePath, outputPath, selection); err != nil {
 			return 0, err
 		}
 		return replaceDurationMs, nil
@@ -517,10 +553,10 @@ func ReplaceSection(ctx context.Context, inputPath, replacePath, outputPath stri
 	}
 	defer os.Remove(partB)
 
-	if err := CopySection(ctx, inputPath, partA, 0, startMs); err != nil {
+	if err := copySectionSelection(ctx, inputPath, partA, 0, startMs, selection); err != nil {
 		return 0, err
 	}
-	if err := CopySection(ctx, inputPath, partB, endMs, inputDurationMs); err != nil {
+	if err := copySectionSelection(ctx, inputPath, partB, endMs, inputDurationMs, selection); err != nil {
 		return 0, err
 	}
 
@@ -530,13 +566,7 @@ func ReplaceSection(ctx context.Context, inputPath, replacePath, outputPath stri
 	}
 	newEndMs := partADurationMs + replaceDurationMs
 
-	listPath, listCleanup, err := createConcatListFile(dir, []string{partA, replacePath, partB})
-	if err != nil {
-		return 0, err
-	}
-	defer listCleanup()
-
-	if err := concatListCopyOrReencode(ctx, listPath, outPath); err != nil {
+	if err := concatPathsSelection(ctx, dir, []string{partA, replacePath, partB}, outPath, selection); err != nil {
 		return 0, err
 	}
 
@@ -138,7 +138,263 @@ func createConcatListFile(dir string, paths []string) (string, func(), error) {
 	return listPath, cleanup, nil
 }
 
+// concatPaths normalizes mismatched audio tracks across paths (the concat
+// demuxer requires identical codec params per track), writes the concat
+// list file, and stitches the result into outPath.
+func concatPaths(ctx context.Context, dir string, paths []string, outPath string) error {
+	return concatPathsSelection(ctx, dir, paths, outPath, StreamSelection{})
+}
+
+// concatPathsSelection is concatPaths with an explicit StreamSelection, so
+// the join stage maps every selected stream (instead of the package
+// default first-video/first-audio) into the joined output.
+func concatPathsSelection(ctx context.Context, dir string, paths []string, outPath string, selection StreamSelection) error {
+	normalizedPaths, normalizeCleanup, err := normalizeConcatAudio(ctx, dir, paths, selection)
+	if err != nil {
+		return err
+	}
+	defer normalizeCleanup()
+
+	mapArgs, err := concatMapArgs(ctx, selection, normalizedPaths[0])
+	if err != nil {
+		return err
+	}
+
+	listPath, listCleanup, err := createConcatListFile(dir, normalizedPaths)
+	if err != nil {
+		return err
+	}
+	defer listCleanup()
+
+	return concatListCopyOrReencodeMaps(ctx, listPath, outPath, mapArgs)
+}
+
+// canonicalConcatAudioParams is the `-c:a aac -ar 48000 -ac 2` target
+// normalizeConcatAudio re-encodes every mismatched clip's audio track to, and
+// reencodeAudioOnly actually encodes to. It must stay a fixed canonical value
+// rather than "whatever the first clip happens to be" — picking it from the
+// first clip would leave that clip unnormalized, so a first clip that isn't
+// already aac/48000/2 (e.g. a 44.1kHz source mixed with a 48kHz one) would
+// still mismatch every re-encoded clip afterwards.
+var canonicalConcatAudioParams = audioParams{codecName: "aac", sampleRate: "48000", channels: "2"}
+
+// concatAudioNeedsNormalizing reports whether any path with audio (per
+// hasAudio) probed with params other than canonicalConcatAudioParams, i.e.
+// whether normalizeConcatAudio has any re-encoding to do.
+func concatAudioNeedsNormalizing(params []audioParams, hasAudio []bool) bool {
+	for i := range params {
+		if hasAudio[i] && params[i] != canonicalConcatAudioParams {
+			return true
+		}
+	}
+	return false
+}
+
+// anyAudioTrackMismatched reports whether any per-path slice of audio track
+// params (as probed by selectedAudioStreamIndexes/probeAudioParamsAt)
+// contains a track other than canonicalConcatAudioParams.
+func anyAudioTrackMismatched(paramsByPath [][]audioParams) bool {
+	for _, params := range paramsByPath {
+		for _, ap := range params {
+			if ap != canonicalConcatAudioParams {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeConcatAudio re-encodes the audio track(s) of any path with a
+// mismatched codec/sample-rate/channel-count, so a plain stream-copy concat
+// can succeed instead of silently falling back to a full re-encode of every
+// track. With the package-default selection (`-map 0:a?`, which maps every
+// audio stream), only each path's first audio stream is probed and checked;
+// a mismatch on a later audio stream does not trigger re-encoding. With a selection that maps more than one audio track (e.g.
+// StreamSelection.AllAudio, for a commentary/second-language track), every
+// selected track of every path is probed and checked — a mismatch on any
+// one of them, not just the first, triggers re-encoding.
+func normalizeConcatAudio(ctx context.Context, dir string, paths []string, selection StreamSelection) ([]string, func(), error) {
+	if selection.isZero() {
+		return normalizeConcatAudioDefault(ctx, dir, paths)
+	}
+	return normalizeConcatAudioSelection(ctx, dir, paths, selection)
+}
+
+func normalizeConcatAudioDefault(ctx context.Context, dir string, paths []string) ([]string, func(), error) {
+	params := make([]audioParams, len(paths))
+	hasAudio := make([]bool, len(paths))
+	for i, p := range paths {
+		ap, ok, err := probeAudioParams(ctx, p)
+		if err != nil {
+			return nil, nil, err
+		}
+		params[i], hasAudio[i] = ap, ok
+	}
+
+	if !concatAudioNeedsNormalizing(params, hasAudio) {
+		return paths, func() {}, nil
+	}
+
+	out := make([]string, len(paths))
+	var cleanups []func()
+	cleanup := func() {
+		for _, c := range cleanups {
+			c()
+		}
+	}
+	for i, p := range paths {
+		if !hasAudio[i] || params[i] == canonicalConcatAudioParams {
+			out[i] = p
+			continue
+		}
+
+		ext := filepath.Ext(p)
+		f, err := os.CreateTemp(dir, "mvedit-concat-audio-*"+ext)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		tmpPath := f.Name()
+		_ = f.Close()
+		cleanups = append(cleanups, func() { _ = os.Remove(tmpPath) })
+
+		if err := reencodeAudioOnly(ctx, p, tmpPath); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		out[i] = tmpPath
+	}
+
+	return out, cleanup, nil
+}
+
+// normalizeConcatAudioSelection is normalizeConcatAudio for a selection that
+// maps more than the package-default single audio track: every audio stream
+// selection selects is probed (by absolute stream index, via
+// selectedAudioStreamIndexes/probeAudioParamsAt) instead of assuming a:0 is
+// the only one that matters.
+func normalizeConcatAudioSelection(ctx context.Context, dir string, paths []string, selection StreamSelection) ([]string, func(), error) {
+	paramsByPath := make([][]audioParams, len(paths))
+	for i, p := range paths {
+		streams, err := ProbeStreams(ctx, p)
+		if err != nil {
+			return nil, nil, err
+		}
+		indexes := selectedAudioStreamIndexes(selection, streams)
+
+		params := make([]audioParams, len(indexes))
+		for j, idx := range indexes {
+			ap, err := probeAudioParamsAt(ctx, p, idx)
+			if err != nil {
+				return nil, nil, err
+			}
+			params[j] = ap
+		}
+		paramsByPath[i] = params
+	}
+
+	if !anyAudioTrackMismatched(paramsByPath) {
+		return paths, func() {}, nil
+	}
+
+	out := make([]string, len(paths))
+	var cleanups []func()
+	cleanup := func() {
+		for _, c := range cleanups {
+			c()
+		}
+	}
+	for i, p := range paths {
+		if !anyAudioTrackMismatched(paramsByPath[i : i+1]) {
+			out[i] = p
+			continue
+		}
+
+		ext := filepath.Ext(p)
+		f, err := os.CreateTemp(dir, "mvedit-concat-audio-*"+ext)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		tmpPath := f.Name()
+		_ = f.Close()
+		cleanups = append(cleanups, func() { _ = os.Remove(tmpPath) })
+
+		if err := reencodeAudioOnlySelection(ctx, p, tmpPath, selection, len(paramsByPath[i])); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		out[i] = tmpPath
+	}
+
+	return out, cleanup, nil
+}
+
+func reencodeAudioOnly(ctx context.Context, inputPath, outputPath string) error {
+	ffmpegArgs := []string{
+		"-y",
+		"-hide_banner",
+		"-loglevel", "error",
+		"-i", inputPath,
+		"-map", "0:v:0",
+		"-map", "0:a?",
+		"-c:v", "copy",
+		"-c:a", "aac",
+		"-ar", "48000",
+		"-ac", "2",
+	}
+	ffmpegArgs = append(ffmpegArgs, movflags(outputPath)...)
+	ffmpegArgs = append(ffmpegArgs, outputPath)
+
+	_, err := runFFmpeg(ctx, ffmpegArgs...)
+	return err
+}
+
+// reencodeAudioOnlySelection is reencodeAudioOnly for a selection that maps
+// more than one audio track: every stream selection maps is kept (video and
+// subtitles stream-copied), but every one of the audioTrackCount selected
+// audio tracks — not just the first — is re-encoded to
+// canonicalConcatAudioParams, addressed via ffmpeg's `:a:N` stream
+// specifiers so a single already-compatible track isn't needlessly touched
+// by the others' re-encode.
+func reencodeAudioOnlySelection(ctx context.Context, inputPath, outputPath string, selection StreamSelection, audioTrackCount int) error {
+	streams, err := ProbeStreams(ctx, inputPath)
+	if err != nil {
+		return err
+	}
+	mapArgs := buildStreamMapArgs(selection, streams)
+
+	ffmpegArgs := []string{
+		"-y",
+		"-hide_banner",
+		"-loglevel", "error",
+		"-i", inputPath,
+	}
+	ffmpegArgs = append(ffmpegArgs, mapArgs...)
+	ffmpegArgs = append(ffmpegArgs, "-c:v", "copy", "-c:s", "copy")
+	for n := 0; n < audioTrackCount; n++ {
+		spec := fmt.Sprintf(":a:%d", n)
+		ffmpegArgs = append(ffmpegArgs, "-c"+spec, "aac", "-ar"+spec, "48000", "-ac"+spec, "2")
+	}
+	ffmpegArgs = append(ffmpegArgs, movflags(outputPath)...)
+	ffmpegArgs = append(ffmpegArgs, outputPath)
+
+	_, err = runFFmpeg(ctx, ffmpegArgs...)
+	return err
+}
+
 func concatListCopyOrReencode(ctx context.Context, listPath, outPath string) error {
+	return concatListCopyOrReencodeMaps(ctx, listPath, outPath, nil)
+}
+
+// concatListCopyOrReencodeMaps is concatListCopyOrReencode with explicit
+// -map args (as built by buildStreamMapArgs). A nil mapArgs means "use the
+// package default `-map 0:v:0 -map 0:a?`".
+func concatListCopyOrReencodeMaps(ctx context.Context, listPath, outPath string, mapArgs []string) error {
+	if mapArgs == nil {
+		mapArgs = []string{"-map", "0:v:0", "-map", "0:a?"}
+	}
+
 	ffmpegCopyArgs := []string{
 		"-y",
 		"-hide_banner",
@@ -146,11 +402,9 @@ func concatListCopyOrReencode(ctx context.Context, listPath, outPath string) err
 		"-f", "concat",
 		"-safe", "0",
 		"-i", listPath,
-		"-map", "0:v:0",
-		"-map", "0:a?",
-		"-c", "copy",
-		"-avoid_negative_ts", "make_zero",
 	}
+	ffmpegCopyArgs = append(ffmpegCopyArgs, mapArgs...)
+	ffmpegCopyArgs = append(ffmpegCopyArgs, "-c", "copy", "-avoid_negative_ts", "make_zero")
 	ffmpegCopyArgs = append(ffmpegCopyArgs, movflags(outPath)...)
 	ffmpegCopyArgs = append(ffmpegCopyArgs, outPath)
 
@@ -159,26 +413,31 @@ func concatListCopyOrReencode(ctx context.Context, listPath, outPath string) err
 		return nil
 	}
 
-	ffmpegReencodeArgs := []string{
-		"-y",
-		"-hide_banner",
-		"-loglevel", "error",
-		"-f", "concat",
-		"-safe", "0",
-		"-i", listPath,
-		"-map", "0:v:0",
-		"-map", "0:a?",
-		"-c:v", "libx264",
-		"-preset", "veryfast",
-		"-crf", "20",
-		"-pix_fmt", "yuv420p",
-		"-c:a", "aac",
-		"-b:a", "192k",
+	buildReencodeArgs := func(profile EncoderProfile) []string {
+		hwaccelArgs, videoArgs := encoderFFmpegArgs(profile)
+		ffmpegReencodeArgs := []string{
+			"-y",
+			"-hide_banner",
+			"-loglevel", "error",
+		}
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, hwaccelArgs...)
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs,
+			"-f", "concat",
+			"-safe", "0",
+			"-i", listPath,
+		)
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, mapArgs...)
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, videoArgs...)
+		if profile == SoftwareX264 {
+			ffmpegReencodeArgs = append(ffmpegReencodeArgs, "-pix_fmt", "yuv420p")
+		}
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, "-c:a", "aac", "-b:a", "192k", "-c:s", "copy")
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, movflags(outPath)...)
+		ffmpegReencodeArgs = append(ffmpegReencodeArgs, outPath)
+		return ffmpegReencodeArgs
 	}
-	ffmpegReencodeArgs = append(ffmpegReencodeArgs, movflags(outPath)...)
-	ffmpegReencodeArgs = append(ffmpegReencodeArgs, outPath)
 
-	reencodeErrOutput, reencodeErr := runFFmpeg(ctx, ffmpegReencodeArgs...)
+	reencodeErrOutput, reencodeErr := runFFmpegReencode(ctx, DefaultEncoderProfile(), buildReencodeArgs)
 	if reencodeErr != nil {
 		return errors.New(strings.TrimSpace("concat-copy failed: " + copyErrOutput + "\nre-encode failed: " + reencodeErrOutput))
 	}
@@ -221,6 +480,10 @@ func pad3(v int) string {
 }
 
 func runFFmpeg(ctx context.Context, args ...string) (string, error) {
+	if sink, totalDurationMs, ok := progressSinkFromContext(ctx); ok {
+		return runFFmpegWithProgress(ctx, sink, totalDurationMs, args...)
+	}
+
 	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
 
 	var stderr bytes.Buffer
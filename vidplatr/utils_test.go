@@ -0,0 +1,53 @@
+package vidplatr
+
+import "testing"
+
+func TestConcatAudioNeedsNormalizing(t *testing.T) {
+	canonical := canonicalConcatAudioParams
+	offTarget := audioParams{codecName: "aac", sampleRate: "44100", channels: "2"}
+
+	tests := []struct {
+		name     string
+		params   []audioParams
+		hasAudio []bool
+		want     bool
+	}{
+		{
+			name:     "all clips already canonical",
+			params:   []audioParams{canonical, canonical},
+			hasAudio: []bool{true, true},
+			want:     false,
+		},
+		{
+			name:     "first clip off target, rest canonical",
+			params:   []audioParams{offTarget, canonical},
+			hasAudio: []bool{true, true},
+			want:     true,
+		},
+		{
+			name:     "only clip is off target",
+			params:   []audioParams{offTarget},
+			hasAudio: []bool{true},
+			want:     true,
+		},
+		{
+			name:     "mismatched clip has no audio track",
+			params:   []audioParams{offTarget, canonical},
+			hasAudio: []bool{false, true},
+			want:     false,
+		},
+		{
+			name:     "no clips have audio",
+			params:   []audioParams{offTarget, offTarget},
+			hasAudio: []bool{false, false},
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := concatAudioNeedsNormalizing(tt.params, tt.hasAudio); got != tt.want {
+				t.Errorf("concatAudioNeedsNormalizing(%+v, %v) = %v, want %v", tt.params, tt.hasAudio, got, tt.want)
+			}
+		})
+	}
+}